@@ -0,0 +1,320 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// AdminTokenHeader is the shared-secret header every /api/admin/ request
+// must present; it's compared against Config.AdminToken (or the
+// TIME_WHISPERER_ADMIN_TOKEN environment variable, for operators who'd
+// rather not put a secret in config.json).
+const AdminTokenHeader = "X-SneakTime-Admin-Token"
+
+// currentConfig is the live, atomically-swappable config every running
+// goroutine should read from once main has started up, as opposed to the
+// cfg value captured at startup. main stores the startup config; after
+// that, publishConfig (called by the admin API and by config file reloads)
+// is the only writer.
+var currentConfig atomic.Pointer[Config]
+
+// ConfigSubscriber is notified after a config change has been validated,
+// persisted and swapped into currentConfig. old is nil only if nothing had
+// been stored before (main stores the startup config directly, without
+// notifying subscribers), so subscribers that only care about changes
+// should check for that before comparing fields.
+type ConfigSubscriber func(old, new *Config)
+
+var (
+	configSubsMu sync.Mutex
+	configSubs   []ConfigSubscriber
+)
+
+// Subscribe registers fn to run every time a new config is published,
+// whether the admin API accepted it or reloadConfigFile read it from disk.
+// Subscribers run synchronously, in registration order, on the publishing
+// goroutine (the PUT handler, the config file watcher or main's signal
+// loop), so they should stay fast (e.g. kick off a restart in a new
+// goroutine rather than blocking inline).
+func Subscribe(fn ConfigSubscriber) {
+	configSubsMu.Lock()
+	defer configSubsMu.Unlock()
+	configSubs = append(configSubs, fn)
+}
+
+// publishConfig swaps new into currentConfig and fans it out to every
+// Subscribe'd observer.
+func publishConfig(new Config) {
+	old := currentConfig.Load()
+	currentConfig.Store(&new)
+
+	configSubsMu.Lock()
+	subs := append([]ConfigSubscriber(nil), configSubs...)
+	configSubsMu.Unlock()
+
+	for _, fn := range subs {
+		fn(old, &new)
+	}
+}
+
+// adminToken resolves the shared secret the admin API requires, preferring
+// the config value and falling back to the environment so operators can
+// keep it out of config.json entirely.
+func adminToken(cfg Config) string {
+	if cfg.AdminToken != "" {
+		return cfg.AdminToken
+	}
+	return os.Getenv("TIME_WHISPERER_ADMIN_TOKEN")
+}
+
+// isLoopbackHost reports whether host (as split from an address by
+// net.SplitHostPort) resolves to the loopback interface.
+func isLoopbackHost(host string) bool {
+	if host == "" || host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// adminErrorBody is the JSON shape every rejected admin request gets back,
+// including which config field triggered the rejection so a caller editing
+// config.json doesn't have to re-derive that from prose.
+type adminErrorBody struct {
+	Error string `json:"error"`
+	Field string `json:"field,omitempty"`
+}
+
+// adminErrorField best-effort extracts the offending field name out of a
+// validateConfig message, whose prose usually either starts with "invalid
+// <field>" or reads "<field> cannot be empty"; the logPath expansion and
+// log directory errors, which do neither, are mapped to "logPath".
+func adminErrorField(msg string) string {
+	if rest, ok := strings.CutPrefix(msg, "invalid "); ok {
+		if i := strings.IndexAny(rest, ": "); i >= 0 {
+			return rest[:i]
+		}
+		return rest
+	}
+	if i := strings.Index(msg, " cannot be empty"); i >= 0 {
+		return msg[:i]
+	}
+	if strings.Contains(msg, "logPath") || strings.Contains(msg, "log directory") {
+		return "logPath"
+	}
+	return ""
+}
+
+func writeAdminError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(adminErrorBody{Error: msg, Field: adminErrorField(msg)})
+}
+
+// registerAdminHandlers wires GET/PUT /api/admin/config/ onto mux, guarded
+// by AdminTokenHeader. cfgPath is where an accepted PUT is persisted, the
+// same file main loaded cfg from at startup.
+func registerAdminHandlers(mux *http.ServeMux, cfgPath string) {
+	mux.HandleFunc("/api/admin/config/", func(w http.ResponseWriter, r *http.Request) {
+		want := adminToken(*currentConfig.Load())
+		if want == "" || r.Header.Get(AdminTokenHeader) != want {
+			writeAdminError(w, http.StatusUnauthorized, "missing or invalid "+AdminTokenHeader)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(redactedConfig(*currentConfig.Load()))
+
+		case http.MethodPut:
+			var updated Config
+			if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+				writeAdminError(w, http.StatusBadRequest, fmt.Sprintf("decode config: %v", err))
+				return
+			}
+			if valid, errMsg := validateConfig(updated); !valid {
+				writeAdminError(w, http.StatusBadRequest, errMsg)
+				return
+			}
+			if err := saveConfig(updated, cfgPath); err != nil {
+				writeAdminError(w, http.StatusInternalServerError, fmt.Sprintf("persist config: %v", err))
+				return
+			}
+			publishConfig(updated)
+			Infof("config", "admin API applied a new config, persisted to %s", cfgPath)
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(redactedConfig(updated))
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// redactedConfig strips AdminToken before a config is ever written back to
+// an HTTP response, so the shared secret a caller authenticated with isn't
+// also echoed back in the body.
+func redactedConfig(cfg Config) Config {
+	cfg.AdminToken = ""
+	return cfg
+}
+
+// monitorSupervisor restarts the primary Upwork tailer against a new
+// directory, without dropping any connected WS/SSE client, when
+// UpworkLogsDir changes via the admin API.
+type monitorSupervisor struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+func (s *monitorSupervisor) start(parent context.Context, dir string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ctx, cancel := context.WithCancel(parent)
+	s.cancel = cancel
+	go runMonitor(ctx, dir)
+}
+
+func (s *monitorSupervisor) restart(parent context.Context, dir string) {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.mu.Unlock()
+	s.start(parent, dir)
+}
+
+// closer returns a CloserFunc suitable for Manager.Register: it cancels the
+// currently-running tailer's context, which closes its fsnotify watcher.
+func (s *monitorSupervisor) closer() CloserFunc {
+	return func() error {
+		s.mu.Lock()
+		cancel := s.cancel
+		s.mu.Unlock()
+		if cancel != nil {
+			cancel()
+		}
+		return nil
+	}
+}
+
+// watchConfigFile watches cfgPath's directory (editors and `cp` both
+// replace-by-rename rather than write-in-place, the same reason the Upwork
+// tailer watches a directory instead of a single *os.File) and reloads the
+// config on every create or write that targets cfgPath (a file renamed onto
+// cfgPath shows up as a create), so an operator
+// can edit config.json by hand and have it picked up without restarting the
+// daemon or going through the admin API. It returns a CloserFunc suitable
+// for Manager.Register.
+//
+// This isn't the only way to trigger reloadConfigFile: the main signal loop
+// also calls it on SIGHUP, alongside SIGHUP's older job of cycling the log
+// verbosity (see cycleLogLevel), for filesystems where fsnotify doesn't fire
+// reliably (NFS, some container bind mounts).
+func watchConfigFile(ctx context.Context, cfgPath string) CloserFunc {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		Errorf("config", "config watcher: %v", err)
+		return func() error { return nil }
+	}
+
+	absPath, err := filepath.Abs(cfgPath)
+	if err != nil {
+		absPath = cfgPath
+	}
+	if err := w.Add(filepath.Dir(absPath)); err != nil {
+		Errorf("config", "config watcher: watch %s: %v", filepath.Dir(absPath), err)
+		w.Close()
+		return func() error { return nil }
+	}
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if ev.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				if filepath.Clean(ev.Name) != absPath {
+					continue
+				}
+				reloadConfigFile(cfgPath)
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				Warnf("config", "config watcher: %v", err)
+			}
+		}
+	}()
+
+	return func() error { return w.Close() }
+}
+
+// reloadConfigFile re-reads cfgPath, validates it, and (only if valid)
+// swaps it into currentConfig via publishConfig, exactly as a PUT to
+// /api/admin/config/ would. An invalid reload backs up the bad file
+// (config.json.bak-<ts>, the same scheme loadConfig already uses for
+// unparseable JSON at startup) and keeps the currently running config, so a
+// typo mid-edit can't take the daemon down.
+func reloadConfigFile(cfgPath string) {
+	b, err := os.ReadFile(cfgPath)
+	if err != nil {
+		Warnf("config", "config reload: read %s: %v", cfgPath, err)
+		return
+	}
+
+	var staged Config
+	if err := json.Unmarshal(b, &staged); err != nil {
+		backupBadConfig(cfgPath, fmt.Sprintf("invalid json: %v", err))
+		return
+	}
+
+	if valid, errMsg := validateConfig(staged); !valid {
+		backupBadConfig(cfgPath, errMsg)
+		return
+	}
+
+	publishConfig(staged)
+	Infof("config", "config reloaded from %s", cfgPath)
+}
+
+// backupBadConfig renames an on-disk config that failed validation or
+// parsing out of the way, the same Windows-safe naming loadConfig uses.
+func backupBadConfig(cfgPath, reason string) {
+	bakPath := fmt.Sprintf("%s.bak-%s", cfgPath, time.Now().UTC().Format("20060102T150405.000000000"))
+	if err := os.Rename(cfgPath, bakPath); err != nil {
+		Warnf("config", "config reload: rejected (%s) but failed to back up: %v", reason, err)
+		return
+	}
+	Warnf("config", "config reload: rejected (%s), backed up to %s, keeping the running config", reason, bakPath)
+}
+
+// startAdminListener starts the admin API on its own listener, separate
+// from the public WS/SSE port, the same way startMetricsListener isolates
+// /metrics and pprof.
+func startAdminListener(addr, cfgPath string) {
+	mux := http.NewServeMux()
+	registerAdminHandlers(mux, cfgPath)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		Errorf("config", "admin listener on %s failed: %v", addr, err)
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAdminConfigRequiresToken(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.AdminToken = "s3cret"
+	currentConfig.Store(&cfg)
+
+	mux := http.NewServeMux()
+	registerAdminHandlers(mux, filepath.Join(t.TempDir(), "config.json"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/config/", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a token, got %d", rec.Code)
+	}
+}
+
+func TestAdminConfigGetReturnsCurrentConfigRedacted(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.AdminToken = "s3cret"
+	cfg.UpworkLogsDir = "/tmp/upwork-logs"
+	currentConfig.Store(&cfg)
+
+	mux := http.NewServeMux()
+	registerAdminHandlers(mux, filepath.Join(t.TempDir(), "config.json"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/config/", nil)
+	req.Header.Set(AdminTokenHeader, "s3cret")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var got Config
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.UpworkLogsDir != "/tmp/upwork-logs" {
+		t.Fatalf("expected upworkLogsDir to round-trip, got %+v", got)
+	}
+	if got.AdminToken != "" {
+		t.Fatalf("expected AdminToken to be redacted, got %q", got.AdminToken)
+	}
+}
+
+func TestAdminConfigPutRejectsInvalidConfigWithField(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.AdminToken = "s3cret"
+	cfg.UpworkLogsDir = "/tmp/upwork-logs"
+	currentConfig.Store(&cfg)
+
+	mux := http.NewServeMux()
+	registerAdminHandlers(mux, filepath.Join(t.TempDir(), "config.json"))
+
+	bad := cfg
+	bad.WebSocketPort = -1
+	body, _ := json.Marshal(bad)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/config/", bytes.NewReader(body))
+	req.Header.Set(AdminTokenHeader, "s3cret")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid config, got %d", rec.Code)
+	}
+	var errBody adminErrorBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &errBody); err != nil {
+		t.Fatalf("decode error body: %v", err)
+	}
+	if errBody.Field != "webSocketPort" {
+		t.Fatalf("expected offending field webSocketPort, got %+v", errBody)
+	}
+}
+
+func TestAdminConfigPutPersistsAndPublishes(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.AdminToken = "s3cret"
+	cfg.UpworkLogsDir = "/tmp/upwork-logs"
+	currentConfig.Store(&cfg)
+
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+
+	var gotOld, gotNew *Config
+	Subscribe(func(old, new *Config) { gotOld, gotNew = old, new })
+
+	mux := http.NewServeMux()
+	registerAdminHandlers(mux, cfgPath)
+
+	updated := cfg
+	updated.UpworkLogsDir = "/tmp/new-upwork-logs"
+	body, _ := json.Marshal(updated)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/config/", bytes.NewReader(body))
+	req.Header.Set(AdminTokenHeader, "s3cret")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if gotNew == nil || gotNew.UpworkLogsDir != "/tmp/new-upwork-logs" {
+		t.Fatalf("expected a subscriber to observe the new config, got %+v", gotNew)
+	}
+	if gotOld == nil || gotOld.UpworkLogsDir != "/tmp/upwork-logs" {
+		t.Fatalf("expected a subscriber to observe the old config, got %+v", gotOld)
+	}
+	if currentConfig.Load().UpworkLogsDir != "/tmp/new-upwork-logs" {
+		t.Fatal("expected currentConfig to be swapped")
+	}
+
+	persisted, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("expected config to be persisted: %v", err)
+	}
+	if !bytes.Contains(persisted, []byte("/tmp/new-upwork-logs")) {
+		t.Fatalf("expected persisted config to contain the new dir, got %s", persisted)
+	}
+}
+
+func TestIsLoopbackHost(t *testing.T) {
+	cases := map[string]bool{
+		"127.0.0.1": true,
+		"localhost": true,
+		"::1":       true,
+		"0.0.0.0":   false,
+		"10.0.0.5":  false,
+	}
+	for host, want := range cases {
+		if got := isLoopbackHost(host); got != want {
+			t.Errorf("isLoopbackHost(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestReloadConfigFileAppliesValidChange(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.UpworkLogsDir = "/tmp/upwork-logs"
+	currentConfig.Store(&cfg)
+
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	updated := cfg
+	updated.UpworkLogsDir = "/tmp/reloaded-logs"
+	b, _ := json.Marshal(updated)
+	if err := os.WriteFile(cfgPath, b, 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	reloadConfigFile(cfgPath)
+
+	if currentConfig.Load().UpworkLogsDir != "/tmp/reloaded-logs" {
+		t.Fatalf("expected currentConfig to be swapped after a valid reload, got %+v", currentConfig.Load())
+	}
+}
+
+func TestReloadConfigFileKeepsRunningConfigOnInvalidJSON(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.UpworkLogsDir = "/tmp/upwork-logs"
+	currentConfig.Store(&cfg)
+
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(cfgPath, []byte("{not valid json"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	reloadConfigFile(cfgPath)
+
+	if currentConfig.Load().UpworkLogsDir != "/tmp/upwork-logs" {
+		t.Fatalf("expected currentConfig to be unchanged after invalid JSON, got %+v", currentConfig.Load())
+	}
+	if _, err := os.Stat(cfgPath); !os.IsNotExist(err) {
+		t.Fatalf("expected the bad config to be renamed out of the way, got err=%v", err)
+	}
+	matches, _ := filepath.Glob(cfgPath + ".bak-*")
+	if len(matches) != 1 {
+		t.Fatalf("expected exactly one backup file, got %v", matches)
+	}
+}
+
+func TestReloadConfigFileKeepsRunningConfigOnFailedValidation(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.UpworkLogsDir = "/tmp/upwork-logs"
+	currentConfig.Store(&cfg)
+
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	bad := cfg
+	bad.WebSocketPort = -1
+	b, _ := json.Marshal(bad)
+	if err := os.WriteFile(cfgPath, b, 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	reloadConfigFile(cfgPath)
+
+	if currentConfig.Load().WebSocketPort == -1 {
+		t.Fatal("expected an invalid webSocketPort to be rejected, not swapped in")
+	}
+	matches, _ := filepath.Glob(cfgPath + ".bak-*")
+	if len(matches) != 1 {
+		t.Fatalf("expected exactly one backup file, got %v", matches)
+	}
+}
+
+func TestValidateConfigRejectsNonLoopbackAdminAddr(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.UpworkLogsDir = "/tmp/upwork-logs"
+	cfg.AdminEnabled = true
+	cfg.AdminAddr = "0.0.0.0:8899"
+
+	if valid, _ := validateConfig(cfg); valid {
+		t.Fatal("expected a non-loopback adminAddr to be rejected without adminAllowRemote")
+	}
+
+	cfg.AdminAllowRemote = true
+	if valid, msg := validateConfig(cfg); !valid {
+		t.Fatalf("expected adminAllowRemote to permit a non-loopback adminAddr, got %q", msg)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// This file is a small, test-mode-only fault injector: a way for
+// integration tests to deliberately break the WS path (dropped upgrades,
+// a connection forced closed mid-broadcast) without racing real network
+// failures, so tests like TestTruncateWhileOpen and
+// TestWebSocketHandshake_AuthenticatedReceivesBroadcast can assert that the
+// monitor/hub actually recover rather than just happening to run fast
+// enough to dodge the bug. It's inert unless faultsActive() says so.
+
+// FaultConfig is the probability/backoff knobs a test can dial in via
+// POST /test/faults, mirroring how /test/broadcast takes a raw WSMessage.
+type FaultConfig struct {
+	// DropUpgradeProbability is the chance (0.0-1.0) handleWebSocket
+	// rejects a /ws upgrade outright with a 503, as if the server were
+	// briefly unavailable.
+	DropUpgradeProbability float64 `json:"dropUpgradeProbability,omitempty"`
+	// BroadcastCloseProbability is the chance (0.0-1.0) sendMessage force-
+	// closes a target's connection before writing to it, simulating a peer
+	// that drops mid-broadcast.
+	BroadcastCloseProbability float64 `json:"broadcastCloseProbability,omitempty"`
+	// BackoffBaseMs/BackoffMaxMs/BackoffJitterMs parameterize faultBackoff,
+	// the retry-backoff helper a caller's own retry loop can use once a
+	// fault has been injected.
+	BackoffBaseMs   int `json:"backoffBaseMs,omitempty"`
+	BackoffMaxMs    int `json:"backoffMaxMs,omitempty"`
+	BackoffJitterMs int `json:"backoffJitterMs,omitempty"`
+}
+
+var (
+	faultsMu   sync.Mutex
+	faultsCfg  FaultConfig
+	faultsFlag bool // set from the -faults CLI flag in main()
+)
+
+// faultsActive reports whether fault injection is live: either GO_TEST=1
+// (which the integration tests set on the daemon they spawn, the same gate
+// /test/broadcast already uses) or the -faults flag was passed at startup.
+func faultsActive() bool {
+	return os.Getenv("GO_TEST") == "1" || faultsFlag
+}
+
+// setFaultConfig replaces the active fault configuration. Safe for
+// concurrent use with the injection checks below.
+func setFaultConfig(cfg FaultConfig) {
+	faultsMu.Lock()
+	faultsCfg = cfg
+	faultsMu.Unlock()
+}
+
+func currentFaultConfig() FaultConfig {
+	faultsMu.Lock()
+	defer faultsMu.Unlock()
+	return faultsCfg
+}
+
+// shouldDropUpgrade rolls against DropUpgradeProbability.
+func shouldDropUpgrade() bool {
+	if !faultsActive() {
+		return false
+	}
+	return rand.Float64() < currentFaultConfig().DropUpgradeProbability
+}
+
+// shouldCloseOnBroadcast rolls against BroadcastCloseProbability.
+func shouldCloseOnBroadcast() bool {
+	if !faultsActive() {
+		return false
+	}
+	return rand.Float64() < currentFaultConfig().BroadcastCloseProbability
+}
+
+// faultBackoff returns the delay before retry attempt n (0-indexed):
+// base*2^n, capped at max, then jittered by up to ±jitter (so a jittered
+// delay can exceed max by at most jitter). Callers with their
+// own retry loops (a future monitor reopen loop, a reconnecting client) use
+// this instead of inventing their own backoff math.
+func faultBackoff(n int) time.Duration {
+	cfg := currentFaultConfig()
+	base, max, jitter := cfg.BackoffBaseMs, cfg.BackoffMaxMs, cfg.BackoffJitterMs
+	if base <= 0 {
+		base = 100
+	}
+	if max <= 0 {
+		max = 5000
+	}
+
+	delay := base << n // base * 2^n
+	if delay > max || delay <= 0 {
+		delay = max
+	}
+	if jitter > 0 {
+		delay += rand.Intn(2*jitter+1) - jitter
+		if delay < 0 {
+			delay = 0
+		}
+	}
+	return time.Duration(delay) * time.Millisecond
+}
+
+// registerFaultHandlers wires POST /test/faults, which accepts a
+// FaultConfig the same way /test/broadcast accepts a WSMessage: only ever
+// under the GO_TEST/-faults/DebugMode gate a caller already checked before
+// registering this handler.
+func registerFaultHandlers(mux *http.ServeMux) {
+	mux.HandleFunc("/test/faults", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var cfg FaultConfig
+		if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		setFaultConfig(cfg)
+		Infof("ws", "test mode: fault config updated: %+v", cfg)
+		w.WriteHeader(http.StatusOK)
+	})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFaultsInactiveByDefault(t *testing.T) {
+	faultsFlag = false
+	setFaultConfig(FaultConfig{DropUpgradeProbability: 1, BroadcastCloseProbability: 1})
+	defer setFaultConfig(FaultConfig{})
+
+	if shouldDropUpgrade() || shouldCloseOnBroadcast() {
+		t.Fatal("expected faults to stay inactive without GO_TEST=1 or -faults")
+	}
+}
+
+func TestFaultsActiveViaFlag(t *testing.T) {
+	faultsFlag = true
+	defer func() { faultsFlag = false }()
+	setFaultConfig(FaultConfig{DropUpgradeProbability: 1})
+	defer setFaultConfig(FaultConfig{})
+
+	if !shouldDropUpgrade() {
+		t.Fatal("expected a probability of 1 to always roll true once faults are active")
+	}
+}
+
+func TestFaultsActiveViaGoTestEnv(t *testing.T) {
+	t.Setenv("GO_TEST", "1")
+	setFaultConfig(FaultConfig{BroadcastCloseProbability: 1})
+	defer setFaultConfig(FaultConfig{})
+
+	if !shouldCloseOnBroadcast() {
+		t.Fatal("expected GO_TEST=1 to activate fault injection")
+	}
+}
+
+func TestFaultBackoffRespectsCapAndBase(t *testing.T) {
+	setFaultConfig(FaultConfig{BackoffBaseMs: 100, BackoffMaxMs: 500, BackoffJitterMs: 0})
+	defer setFaultConfig(FaultConfig{})
+
+	if got := faultBackoff(0); got.Milliseconds() != 100 {
+		t.Fatalf("expected the first attempt to wait the base delay, got %s", got)
+	}
+	if got := faultBackoff(10); got.Milliseconds() != 500 {
+		t.Fatalf("expected a large attempt number to be capped at the max, got %s", got)
+	}
+}
+
+func TestRegisterFaultHandlersUpdatesConfig(t *testing.T) {
+	setFaultConfig(FaultConfig{})
+	defer setFaultConfig(FaultConfig{})
+
+	mux := http.NewServeMux()
+	registerFaultHandlers(mux)
+
+	body, _ := json.Marshal(FaultConfig{DropUpgradeProbability: 0.5})
+	req := httptest.NewRequest(http.MethodPost, "/test/faults", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if got := currentFaultConfig().DropUpgradeProbability; got != 0.5 {
+		t.Fatalf("expected the posted config to take effect, got %v", got)
+	}
+}
+
+func TestRegisterFaultHandlersRejectsNonPost(t *testing.T) {
+	mux := http.NewServeMux()
+	registerFaultHandlers(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/test/faults", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rec.Code)
+	}
+}
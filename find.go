@@ -0,0 +1,153 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// logFlag collects repeated --log PATH flags into a slice.
+type logFlag []string
+
+func (l *logFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *logFlag) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// parseTimeBoundary understands "now", "<duration> ago" (e.g. "24h ago"),
+// RFC3339 (which carries its own zone offset or Z), and the native Upwork
+// layout used by parseTS ("2006-01-02T15:04:05.000": zone-less, with
+// milliseconds required). A native-layout timestamp is interpreted in loc.
+func parseTimeBoundary(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "now" {
+		return time.Now(), nil
+	}
+	if rest, ok := strings.CutSuffix(s, "ago"); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid relative time %q: %w", s, err)
+		}
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05.000", s, loc); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q (want RFC3339, \"2006-01-02T15:04:05.000\", \"now\", or \"<duration> ago\")", s)
+}
+
+// resolveLocation turns --tz/--utc into the *time.Location used to both
+// interpret --oldest/--newest and display results. An empty tz with utc
+// false keeps the historical local-time behavior.
+func resolveLocation(tz string, utc bool) (*time.Location, error) {
+	if utc {
+		return time.UTC, nil
+	}
+	if tz == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --tz %q: %w", tz, err)
+	}
+	return loc, nil
+}
+
+// resolveLogsDir figures out which directory to search when the caller
+// didn't pass an explicit --log flag: the UPWORK_LOGS_DIR env var, falling
+// back to whatever is configured on disk.
+func resolveLogsDir() (string, error) {
+	if dir := os.Getenv("UPWORK_LOGS_DIR"); dir != "" {
+		return dir, nil
+	}
+	cfg, _, err := loadConfig(getConfigPath())
+	if err != nil {
+		return "", err
+	}
+	return cfg.UpworkLogsDir, nil
+}
+
+// runFind implements the `find` subcommand (modeled on restic's find): it
+// scans one or more Upwork logs and prints every screenshot event whose
+// timestamp falls within [--oldest, --newest].
+func runFind(args []string) int {
+	fs := flag.NewFlagSet("find", flag.ExitOnError)
+	var logs logFlag
+	fs.Var(&logs, "log", "log file to search (repeatable); defaults to every upwork..*.log in the configured logs dir")
+	oldestStr := fs.String("oldest", "24h ago", `lower time bound: RFC3339, the native layout, "now", or "<duration> ago"`)
+	newestStr := fs.String("newest", "now", "upper time bound, same formats as --oldest")
+	jsonOut := fs.Bool("json", false, "emit one JSON object per screenshot event instead of tab-separated text")
+	tzStr := fs.String("tz", "", "IANA zone to interpret bare --oldest/--newest values in and display text results in (default: local time); --json output is always UTC")
+	utcFlag := fs.Bool("utc", false, "shorthand for --tz=UTC")
+	fs.Parse(args)
+
+	loc, err := resolveLocation(*tzStr, *utcFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "find: %v\n", err)
+		return 1
+	}
+
+	oldest, err := parseTimeBoundary(*oldestStr, loc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "find: %v\n", err)
+		return 1
+	}
+	newest, err := parseTimeBoundary(*newestStr, loc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "find: %v\n", err)
+		return 1
+	}
+
+	files := []string(logs)
+	if len(files) == 0 {
+		dir, err := resolveLogsDir()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "find: %v\n", err)
+			return 1
+		}
+		files = findAllLogs(dir)
+	}
+
+	seen := make(map[string]bool)
+	var events []ScreenshotEvent
+	for _, f := range files {
+		for _, e := range getAllScreenshotTimestamps(f, oldest, newest) {
+			key := e.TS.Format(time.RFC3339Nano)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			events = append(events, e)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].TS.Before(events[j].TS) })
+
+	if len(events) == 0 {
+		fmt.Fprintln(os.Stderr, "find: no screenshots in range")
+		return 0
+	}
+
+	if *jsonOut {
+		if err := emitJSON(os.Stdout, events); err != nil {
+			fmt.Fprintf(os.Stderr, "find: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	for _, e := range events {
+		fmt.Printf("%s\t%s\n", e.TS.In(loc).Format(time.RFC3339Nano), e.Log)
+	}
+	return 0
+}
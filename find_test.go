@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeBoundary(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+
+	got, err := parseTimeBoundary("2025-04-10T18:45:30.456", ny)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Location() != ny {
+		t.Fatalf("expected zone-less timestamp to be interpreted in %v, got %v", ny, got.Location())
+	}
+
+	got, err = parseTimeBoundary("2025-04-10T18:45:30.456+09:00", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, off := got.Zone(); off != 9*3600 {
+		t.Fatalf("expected +09:00 offset to be preserved, got offset %d", off)
+	}
+
+	if _, err := parseTimeBoundary("not a time", time.UTC); err == nil {
+		t.Fatal("expected error for unparsable boundary")
+	}
+}
+
+func TestResolveLocation(t *testing.T) {
+	loc, err := resolveLocation("", false)
+	if err != nil || loc != time.Local {
+		t.Fatalf("expected default to be time.Local, got %v, err %v", loc, err)
+	}
+
+	loc, err = resolveLocation("ignored", true)
+	if err != nil || loc != time.UTC {
+		t.Fatalf("--utc should win over --tz, got %v, err %v", loc, err)
+	}
+
+	if _, err := resolveLocation("Not/AZone", false); err == nil {
+		t.Fatal("expected error for invalid IANA zone name")
+	}
+}
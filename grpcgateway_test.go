@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGatewayStatusReturnsVersion(t *testing.T) {
+	mux := http.NewServeMux()
+	registerGatewayHandlers(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/status", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var got gatewayStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.Version != Version {
+		t.Fatalf("expected version %q, got %q", Version, got.Version)
+	}
+}
+
+func TestGatewayConfigRedactsAdminToken(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.AdminToken = "s3cret"
+	currentConfig.Store(&cfg)
+
+	mux := http.NewServeMux()
+	registerGatewayHandlers(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/config", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	var got Config
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.AdminToken != "" {
+		t.Fatalf("expected AdminToken to be redacted, got %q", got.AdminToken)
+	}
+}
+
+func TestGatewayBroadcastAcceptsPost(t *testing.T) {
+	mux := http.NewServeMux()
+	registerGatewayHandlers(mux)
+
+	body, _ := json.Marshal(WSMessage{Type: "ping"})
+	req := httptest.NewRequest(http.MethodPost, "/v1/broadcast", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+}
+
+func TestGatewayBroadcastRejectsNonPost(t *testing.T) {
+	mux := http.NewServeMux()
+	registerGatewayHandlers(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/broadcast", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rec.Code)
+	}
+}
+
+func TestValidateConfigRejectsInvalidGRPCPort(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.UpworkLogsDir = "/tmp/upwork-logs"
+	cfg.GatewayEnabled = true
+	cfg.GRPCPort = 70000
+
+	if valid, _ := validateConfig(cfg); valid {
+		t.Fatal("expected an out-of-range grpcPort to be rejected when gatewayEnabled is true")
+	}
+
+	cfg.GRPCPort = 8898
+	if valid, msg := validateConfig(cfg); !valid {
+		t.Fatalf("expected a valid grpcPort to pass validation, got %q", msg)
+	}
+}
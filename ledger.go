@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// This file replaces file-position tracking as the source of truth for
+// "don't re-report a screenshot detection" with an explicit, persistent
+// ledger keyed by the SHA-256 of the detected line itself. Unlike seeking
+// to the end of the log on open (which only prevents re-reading history,
+// and says nothing about a line genuinely seen twice, e.g. the same minute
+// surviving a copy-truncate), a content hash dedups correctly across
+// restarts, renames, and rotations uniformly.
+
+// ledgerEntry is one line recorded to the ledger file.
+type ledgerEntry struct {
+	Timestamp  time.Time `json:"timestamp"`
+	LogFile    string    `json:"logFile"`
+	ByteOffset int64     `json:"byteOffset"`
+	LineHash   string    `json:"lineHash"`
+}
+
+// DetectionLedger is an append-only WAL of detected lines plus the in-
+// memory index (bounded by window/maxEntries) runMonitor consults before
+// reporting a detection.
+type DetectionLedger struct {
+	path string
+	mu   sync.Mutex
+
+	window     time.Duration
+	maxEntries int
+
+	seen  map[string]ledgerEntry // lineHash -> entry, bounded by window/maxEntries
+	total int                    // entries appended to the file since the last compaction
+}
+
+// globalLedger is nil until main() successfully provisions one; runMonitor
+// falls back to its existing in-process seen map when it's nil, the same
+// "persistence disabled" convention globalStore already uses.
+var globalLedger *DetectionLedger
+
+// newDetectionLedger opens (creating if necessary) the ledger at path,
+// loading every entry newer than window into memory. An empty path
+// defaults to ~/.time-whisperer/detections.log. maxEntries additionally
+// bounds the in-memory index regardless of age, dropping the oldest first.
+func newDetectionLedger(path string, window time.Duration, maxEntries int) (*DetectionLedger, error) {
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, ".time-whisperer", "detections.log")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	l := &DetectionLedger{
+		path:       path,
+		window:     window,
+		maxEntries: maxEntries,
+		seen:       make(map[string]ledgerEntry),
+	}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func lineHash(line string) string {
+	sum := sha256.Sum256([]byte(line))
+	return hex.EncodeToString(sum[:])
+}
+
+func (l *DetectionLedger) load() error {
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cutoff := time.Now().Add(-l.window)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e ledgerEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue // tolerate a partially-written trailing line
+		}
+		l.total++
+		if e.Timestamp.Before(cutoff) {
+			continue
+		}
+		l.seen[e.LineHash] = e
+	}
+	l.trimToMaxLocked()
+	return scanner.Err()
+}
+
+// Seen reports whether line's hash is already in the in-memory index.
+func (l *DetectionLedger) Seen(line string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.seen[lineHash(line)]
+	return ok
+}
+
+// Record appends a new WAL entry for line and adds it to the index. It
+// first drops index entries that have aged out of the window, then
+// compacts the on-disk file if it holds more than 100 entries and more than
+// half of them are no longer in the index.
+func (l *DetectionLedger) Record(line, logFile string, byteOffset int64, ts time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := time.Now().Add(-l.window)
+	for h, e := range l.seen {
+		if e.Timestamp.Before(cutoff) {
+			delete(l.seen, h)
+		}
+	}
+
+	if l.total > 2*len(l.seen) && l.total > 100 {
+		if err := l.compactLocked(); err != nil {
+			Warnf("monitor", "detection ledger: compaction failed: %v", err)
+		}
+	}
+
+	e := ledgerEntry{Timestamp: ts, LogFile: logFile, ByteOffset: byteOffset, LineHash: lineHash(line)}
+
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := json.NewEncoder(f).Encode(e); err != nil {
+		return err
+	}
+	l.total++
+	l.seen[e.LineHash] = e
+	l.trimToMaxLocked()
+	return nil
+}
+
+// trimToMaxLocked drops the oldest entries once the in-memory index exceeds
+// maxEntries. Must be called with mu held.
+func (l *DetectionLedger) trimToMaxLocked() {
+	if l.maxEntries <= 0 || len(l.seen) <= l.maxEntries {
+		return
+	}
+	type kv struct {
+		hash string
+		ts   time.Time
+	}
+	entries := make([]kv, 0, len(l.seen))
+	for h, e := range l.seen {
+		entries = append(entries, kv{h, e.Timestamp})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ts.Before(entries[j].ts) })
+	for _, e := range entries[:len(entries)-l.maxEntries] {
+		delete(l.seen, e.hash)
+	}
+}
+
+// compactLocked rewrites the ledger file to hold exactly the entries
+// currently in the in-memory index, dropping everything that's aged out.
+// Must be called with mu held.
+func (l *DetectionLedger) compactLocked() error {
+	tmp := l.path + ".compact"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, e := range l.seen {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return err
+	}
+	l.total = len(l.seen)
+	return nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDetectionLedgerRecordThenSeen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "detections.log")
+	l, err := newDetectionLedger(path, 24*time.Hour, 0)
+	if err != nil {
+		t.Fatalf("newDetectionLedger: %v", err)
+	}
+
+	line := "2026-07-26T10:00:00.000 Electron Screensnap succeeded"
+	if l.Seen(line) {
+		t.Fatal("expected a fresh line to be unseen")
+	}
+	if err := l.Record(line, "upwork.1.log", 42, time.Now()); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if !l.Seen(line) {
+		t.Fatal("expected the recorded line to be seen")
+	}
+}
+
+func TestDetectionLedgerSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "detections.log")
+	line := "2026-07-26T10:00:00.000 Electron Screensnap succeeded"
+
+	first, err := newDetectionLedger(path, 24*time.Hour, 0)
+	if err != nil {
+		t.Fatalf("newDetectionLedger: %v", err)
+	}
+	if err := first.Record(line, "upwork.1.log", 0, time.Now()); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	second, err := newDetectionLedger(path, 24*time.Hour, 0)
+	if err != nil {
+		t.Fatalf("newDetectionLedger (reload): %v", err)
+	}
+	if !second.Seen(line) {
+		t.Fatal("expected a line recorded before restart to still be seen after reloading the ledger")
+	}
+}
+
+func TestDetectionLedgerDropsEntriesOutsideWindow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "detections.log")
+	l, err := newDetectionLedger(path, time.Hour, 0)
+	if err != nil {
+		t.Fatalf("newDetectionLedger: %v", err)
+	}
+
+	oldLine := "2020-01-01T00:00:00.000 Electron Screensnap succeeded"
+	if err := l.Record(oldLine, "upwork.1.log", 0, time.Now().Add(-48*time.Hour)); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	reloaded, err := newDetectionLedger(path, time.Hour, 0)
+	if err != nil {
+		t.Fatalf("newDetectionLedger (reload): %v", err)
+	}
+	if reloaded.Seen(oldLine) {
+		t.Fatal("expected an entry older than the window to be dropped on load")
+	}
+}
+
+func TestDetectionLedgerTrimsToMaxEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "detections.log")
+	l, err := newDetectionLedger(path, 24*time.Hour, 2)
+	if err != nil {
+		t.Fatalf("newDetectionLedger: %v", err)
+	}
+
+	now := time.Now()
+	for i, line := range []string{"line-a", "line-b", "line-c"} {
+		if err := l.Record(line, "upwork.1.log", int64(i), now.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	if l.Seen("line-a") {
+		t.Fatal("expected the oldest entry to be trimmed once maxEntries is exceeded")
+	}
+	if !l.Seen("line-b") || !l.Seen("line-c") {
+		t.Fatal("expected the two most recent entries to remain")
+	}
+}
+
+func TestDetectionLedgerCompactsOnRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "detections.log")
+	l, err := newDetectionLedger(path, time.Hour, 0)
+	if err != nil {
+		t.Fatalf("newDetectionLedger: %v", err)
+	}
+
+	// Record the same line repeatedly so total grows while the in-memory
+	// index (keyed by line hash) stays at a single entry, crossing the 2x
+	// threshold that triggers compaction on a later Record.
+	now := time.Now()
+	const calls = 102 // first call past total>100 with len(seen)==1 triggers compaction
+	for i := 0; i < calls; i++ {
+		if err := l.Record("stale-line", "upwork.1.log", int64(i), now); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	if !l.Seen("stale-line") {
+		t.Fatal("expected the repeatedly-recorded line to still be seen after compaction")
+	}
+	if l.total > len(l.seen)+1 {
+		t.Fatalf("expected compaction to shrink the on-disk ledger close to the in-memory index, got total=%d seen=%d", l.total, len(l.seen))
+	}
+}
+
+func TestNewDetectionLedgerDefaultsPath(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	l, err := newDetectionLedger("", time.Hour, 0)
+	if err != nil {
+		t.Fatalf("newDetectionLedger: %v", err)
+	}
+	if filepath.Base(l.path) != "detections.log" {
+		t.Fatalf("expected the default path to end in detections.log, got %s", l.path)
+	}
+}
+
+func TestValidateConfigRejectsNegativeLedgerWindow(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.UpworkLogsDir = "/tmp/upwork-logs"
+	cfg.DetectionLedgerWindowHours = -1
+
+	if valid, _ := validateConfig(cfg); valid {
+		t.Fatal("expected a negative detectionLedgerWindowHours to be rejected")
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestParseTraceCategories(t *testing.T) {
+	set := parseTraceCategories(" ws, monitor ,,config")
+	for _, want := range []string{"ws", "monitor", "config"} {
+		if !set[want] {
+			t.Fatalf("expected %q to be enabled, got %v", want, set)
+		}
+	}
+	if len(set) != 3 {
+		t.Fatalf("expected 3 categories, got %v", set)
+	}
+
+	if len(parseTraceCategories("")) != 0 {
+		t.Fatalf("expected no categories for an empty string")
+	}
+}
+
+func TestCategoryEnabled(t *testing.T) {
+	orig := traceCategories
+	defer func() { traceCategories = orig }()
+
+	traceCategories = map[string]bool{"ws": true}
+	if !categoryEnabled("ws") {
+		t.Fatal("expected ws to be enabled")
+	}
+	if categoryEnabled("monitor") {
+		t.Fatal("expected monitor to stay silenced")
+	}
+
+	traceCategories = map[string]bool{"all": true}
+	if !categoryEnabled("monitor") {
+		t.Fatal("expected TW_TRACE=all to enable every category")
+	}
+}
+
+func TestEmitLogTextFormat(t *testing.T) {
+	origFormat, origOut := logFormat, log.Writer()
+	defer func() { logFormat = origFormat; log.SetOutput(origOut) }()
+	logFormat = "text"
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+
+	emitLog(levelWarn, "ws", "write to 127.0.0.1:9 failed: broken pipe", nil)
+
+	if !strings.Contains(buf.String(), "[warn][ws] write to 127.0.0.1:9 failed: broken pipe") {
+		t.Fatalf("unexpected text log line: %q", buf.String())
+	}
+}
+
+func TestEmitLogJSONFormat(t *testing.T) {
+	origFormat, origOut := logFormat, log.Writer()
+	defer func() { logFormat = origFormat; log.SetOutput(origOut) }()
+	logFormat = "json"
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+
+	emitLog(levelInfo, "ws", "Authentication successful", map[string]any{"conn_addr": "127.0.0.1:9"})
+
+	var rec logRecord
+	if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &rec); err != nil {
+		t.Fatalf("expected a JSON log line, got %q: %v", buf.String(), err)
+	}
+	if rec.Level != levelInfo || rec.Category != "ws" || rec.Msg != "Authentication successful" {
+		t.Fatalf("unexpected log record: %+v", rec)
+	}
+	if rec.Fields["conn_addr"] != "127.0.0.1:9" {
+		t.Fatalf("expected conn_addr field to round-trip, got %+v", rec.Fields)
+	}
+}
+
+func TestSetLogLevelRejectsUnknownLevel(t *testing.T) {
+	orig := minLevel.Load()
+	defer minLevel.Store(orig)
+
+	if err := setLogLevel("verbose"); err == nil {
+		t.Fatal("expected an error for an unrecognized log level")
+	}
+	if err := setLogLevel(" Warn "); err != nil {
+		t.Fatalf("expected \"Warn\" to parse case/space-insensitively, got %v", err)
+	}
+	if minLevel.Load() != int32(levelRank[levelWarn]) {
+		t.Fatalf("expected minLevel to be warn, got rank %d", minLevel.Load())
+	}
+}
+
+func TestMinLevelFiltersBelowFloor(t *testing.T) {
+	origLevel, origOut := minLevel.Load(), log.Writer()
+	defer func() { minLevel.Store(origLevel); log.SetOutput(origOut) }()
+
+	if err := setLogLevel("warn"); err != nil {
+		t.Fatalf("setLogLevel: %v", err)
+	}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+
+	emitLog(levelInfo, "ws", "should be dropped below the warn floor", nil)
+	if buf.Len() != 0 {
+		t.Fatalf("expected info record to be filtered out at the warn floor, got %q", buf.String())
+	}
+
+	emitLog(levelWarn, "ws", "should still be emitted", nil)
+	if !strings.Contains(buf.String(), "should still be emitted") {
+		t.Fatalf("expected warn record to pass the warn floor, got %q", buf.String())
+	}
+}
+
+func TestCycleLogLevelWrapsAround(t *testing.T) {
+	orig := minLevel.Load()
+	defer minLevel.Store(orig)
+
+	minLevel.Store(int32(levelRank[levelError]))
+	if got := cycleLogLevel(); got != levelDebug {
+		t.Fatalf("expected cycling past error to wrap to debug, got %q", got)
+	}
+}
+
+func TestValidateConfigRejectsInvalidLogFormat(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.UpworkLogsDir = "/tmp/upwork-logs"
+	cfg.LogFormat = "xml"
+
+	if valid, _ := validateConfig(cfg); valid {
+		t.Fatal("expected an unrecognized logFormat to be rejected")
+	}
+
+	for _, format := range []string{"", "text", "json"} {
+		cfg.LogFormat = format
+		if valid, msg := validateConfig(cfg); !valid {
+			t.Fatalf("expected logFormat %q to be valid, got %q", format, msg)
+		}
+	}
+}
+
+func TestNextConnIDIsUniqueAndIncreasing(t *testing.T) {
+	a := nextConnID()
+	b := nextConnID()
+	if a == b {
+		t.Fatalf("expected distinct conn IDs, got %q twice", a)
+	}
+
+	var na, nb uint64
+	if _, err := fmt.Sscanf(a, "c%d", &na); err != nil {
+		t.Fatalf("unexpected conn ID format %q: %v", a, err)
+	}
+	if _, err := fmt.Sscanf(b, "c%d", &nb); err != nil {
+		t.Fatalf("unexpected conn ID format %q: %v", b, err)
+	}
+	if nb <= na {
+		t.Fatalf("expected conn IDs to increase, got %q then %q", a, b)
+	}
+}
+
+func TestDebugfSilencedByDefault(t *testing.T) {
+	origCats, origOut := traceCategories, log.Writer()
+	defer func() { traceCategories = origCats; log.SetOutput(origOut) }()
+	traceCategories = map[string]bool{}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+
+	Debugf("monitor", "this should never appear")
+	if buf.Len() != 0 {
+		t.Fatalf("expected Debugf to be silenced without TW_TRACE, got %q", buf.String())
+	}
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// SourceConfig describes one pluggable log source in config.json. Type
+// "upwork" needs nothing beyond a name (an empty Dir means the Upwork logs
+// directory is auto-discovered; the configured UpworkLogsDir is not
+// consulted); type "rules" drives a generic regex-based
+// tailer for other desktop apps (Toggl, Hubstaff, custom loggers, ...).
+type SourceConfig struct {
+	Type        string   `json:"type"`
+	Name        string   `json:"name"`
+	Dir         string   `json:"dir,omitempty"`
+	Glob        string   `json:"glob,omitempty"`
+	Regex       string   `json:"regex,omitempty"`
+	TimeLayouts []string `json:"timeLayouts,omitempty"`
+}
+
+// SourceEvent is one match produced by a Source's Match method.
+type SourceEvent struct {
+	Timestamp time.Time
+	EventType string
+	Raw       string
+}
+
+// Source abstracts "where do I find logs, and which lines matter" so
+// runGenericSource can tail Toggl, Hubstaff, or any line-oriented log
+// without the daemon knowing anything app-specific.
+type Source interface {
+	Name() string
+	Discover() (dir string, err error)
+	LatestFile(dir string) string
+	Match(line string) (SourceEvent, bool)
+}
+
+// buildSource resolves a SourceConfig into a ready-to-use Source.
+func buildSource(cfg SourceConfig) (Source, error) {
+	switch cfg.Type {
+	case "upwork", "":
+		name := cfg.Name
+		if name == "" {
+			name = "upwork"
+		}
+		return &upworkSource{name: name, dir: cfg.Dir}, nil
+	case "rules":
+		return newRulesSource(cfg)
+	default:
+		return nil, fmt.Errorf("logsource: unknown source type %q", cfg.Type)
+	}
+}
+
+// upworkSource wraps the original, hardcoded Upwork behavior (discovery,
+// rotated-file lookup, and the "Electron Screensnap succeeded" match) behind
+// the Source interface, reusing the same helpers findLatestLog/parseTS/
+// screenshotPattern already rely on elsewhere (find.go, stats.go, tail.go).
+type upworkSource struct {
+	name string
+	dir  string // explicit override; empty means "discover"
+}
+
+func (s *upworkSource) Name() string { return s.name }
+
+func (s *upworkSource) Discover() (string, error) {
+	if s.dir != "" {
+		return expandPath(s.dir), nil
+	}
+	if dir := discoverUpworkLogsDir(); dir != "" {
+		return dir, nil
+	}
+	return "", fmt.Errorf("logsource: could not discover an Upwork logs directory")
+}
+
+func (s *upworkSource) LatestFile(dir string) string { return findLatestLog(dir) }
+
+func (s *upworkSource) Match(line string) (SourceEvent, bool) {
+	if !strings.Contains(line, screenshotPattern) {
+		return SourceEvent{}, false
+	}
+	ts := parseTS(line)
+	if ts.IsZero() {
+		return SourceEvent{}, false
+	}
+	return SourceEvent{Timestamp: ts, EventType: "screenshot", Raw: line}, true
+}
+
+// rulesSource is the generic, JSON-configured adapter: a glob to find log
+// files, a regex with named "timestamp" and "event" capture groups, and a
+// list of time layouts tried in order against the "timestamp" group.
+type rulesSource struct {
+	name    string
+	dir     string
+	glob    string
+	re      *regexp.Regexp
+	layouts []string
+}
+
+func newRulesSource(cfg SourceConfig) (*rulesSource, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("logsource: rules source requires a name")
+	}
+	if cfg.Glob == "" {
+		return nil, fmt.Errorf("logsource: rules source %q requires a glob", cfg.Name)
+	}
+	re, err := regexp.Compile(cfg.Regex)
+	if err != nil {
+		return nil, fmt.Errorf("logsource: rules source %q: invalid regex: %w", cfg.Name, err)
+	}
+	if i := indexOfString(re.SubexpNames(), "timestamp"); i < 0 {
+		return nil, fmt.Errorf("logsource: rules source %q: regex must have a (?P<timestamp>...) group", cfg.Name)
+	}
+	if i := indexOfString(re.SubexpNames(), "event"); i < 0 {
+		return nil, fmt.Errorf("logsource: rules source %q: regex must have a (?P<event>...) group", cfg.Name)
+	}
+
+	layouts := cfg.TimeLayouts
+	if len(layouts) == 0 {
+		layouts = []string{time.RFC3339Nano, time.RFC3339, "2006-01-02T15:04:05.000"}
+	}
+
+	return &rulesSource{name: cfg.Name, dir: cfg.Dir, glob: cfg.Glob, re: re, layouts: layouts}, nil
+}
+
+func indexOfString(ss []string, want string) int {
+	for i, s := range ss {
+		if s == want {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *rulesSource) Name() string { return s.name }
+
+func (s *rulesSource) Discover() (string, error) {
+	if s.dir == "" {
+		return "", fmt.Errorf("logsource: rules source %q has no configured dir", s.name)
+	}
+	return expandPath(s.dir), nil
+}
+
+func (s *rulesSource) LatestFile(dir string) string {
+	matches, _ := filepath.Glob(filepath.Join(dir, s.glob))
+	var latest string
+	var latestMod time.Time
+	for _, f := range matches {
+		if fi, err := os.Stat(f); err == nil && fi.ModTime().After(latestMod) {
+			latest, latestMod = f, fi.ModTime()
+		}
+	}
+	return latest
+}
+
+func (s *rulesSource) Match(line string) (SourceEvent, bool) {
+	m := s.re.FindStringSubmatch(line)
+	if m == nil {
+		return SourceEvent{}, false
+	}
+	names := s.re.SubexpNames()
+	var rawTS, eventType string
+	for i, name := range names {
+		switch name {
+		case "timestamp":
+			rawTS = m[i]
+		case "event":
+			eventType = m[i]
+		}
+	}
+
+	for _, layout := range s.layouts {
+		if ts, err := time.ParseInLocation(layout, rawTS, time.Local); err == nil {
+			return SourceEvent{Timestamp: ts, EventType: eventType, Raw: line}, true
+		}
+	}
+	return SourceEvent{}, false
+}
+
+// runGenericSource mirrors runMonitor's tailing loop (rotation-aware,
+// dedup-by-timestamp) but drives it entirely off the Source interface, so
+// any configured SourceConfig can be watched without app-specific code in
+// main.go. It's used for every cfg.Sources entry; the original Upwork path
+// still runs through runMonitor directly to leave that well-exercised loop
+// untouched.
+func runGenericSource(ctx context.Context, src Source) {
+	dir, err := src.Discover()
+	if err != nil {
+		Errorf("monitor", "logsource %s: %v", src.Name(), err)
+		return
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		Errorf("fsnotify", "logsource %s: %v", src.Name(), err)
+		return
+	}
+	defer w.Close()
+
+	if err := w.Add(dir); err != nil {
+		Errorf("fsnotify", "logsource %s: watch %s: %v", src.Name(), dir, err)
+		return
+	}
+
+	var (
+		current  *os.File
+		rdr      *bufio.Reader
+		lastSeen time.Time
+	)
+
+	openCurrent := func() error {
+		fname := src.LatestFile(dir)
+		if fname == "" || (current != nil && current.Name() == fname) {
+			return nil
+		}
+		if current != nil {
+			current.Close()
+			current, rdr = nil, nil
+		}
+		f, err := os.Open(fname)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Seek(0, io.SeekEnd); err != nil {
+			f.Close()
+			return err
+		}
+		current, rdr = f, bufio.NewReaderSize(f, 64*1024)
+		Infof("monitor", "logsource %s: monitoring %s", src.Name(), fname)
+		return nil
+	}
+
+	if err := openCurrent(); err != nil {
+		Warnf("monitor", "logsource %s: initial open failed, will retry: %v", src.Name(), err)
+	}
+
+	drainNew := func() {
+		for current != nil {
+			if off, _ := current.Seek(0, io.SeekCurrent); off > 0 {
+				if fi, err := current.Stat(); err == nil && fi.Size() < off {
+					if _, err := current.Seek(0, io.SeekStart); err == nil {
+						rdr.Reset(current)
+					}
+				}
+			}
+
+			line, err := rdr.ReadString('\n')
+			if err != nil {
+				if err != io.EOF {
+					if openErr := openCurrent(); openErr != nil {
+						Warnf("monitor", "logsource %s: reopen after read error: %v", src.Name(), openErr)
+					}
+				}
+				return
+			}
+
+			ev, ok := src.Match(line)
+			if !ok || ev.Timestamp.IsZero() || !ev.Timestamp.After(lastSeen) {
+				continue
+			}
+			lastSeen = ev.Timestamp
+			notifyEvent(src.Name(), ev.EventType, ev.Timestamp, map[string]any{"raw": ev.Raw})
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if current != nil {
+				current.Close()
+			}
+			return
+
+		case ev := <-w.Events:
+			if ev.Op&(fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0 {
+				if err := openCurrent(); err != nil {
+					Warnf("monitor", "logsource %s: rescan after %s event: %v", src.Name(), ev.Op, err)
+				}
+			}
+			if current == nil || ev.Name != current.Name() || ev.Op&fsnotify.Write == 0 {
+				continue
+			}
+			drainNew()
+
+		case err := <-w.Errors:
+			Warnf("fsnotify", "logsource %s: watch error: %v", src.Name(), err)
+		}
+	}
+}
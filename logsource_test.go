@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUpworkSourceMatch(t *testing.T) {
+	src := &upworkSource{name: "upwork"}
+
+	ev, ok := src.Match("[2025-04-10T18:45:30.456] [INFO] Electron Screensnap succeeded.")
+	if !ok {
+		t.Fatal("expected a match on an Electron Screensnap succeeded line")
+	}
+	if ev.EventType != "screenshot" {
+		t.Fatalf("expected eventType screenshot, got %q", ev.EventType)
+	}
+	if _, ok := src.Match("[2025-04-10T18:45:30.456] [INFO] unrelated line"); ok {
+		t.Fatal("expected no match on an unrelated line")
+	}
+}
+
+func TestNewRulesSourceRequiresTimestampAndEventGroups(t *testing.T) {
+	if _, err := newRulesSource(SourceConfig{Name: "toggl", Glob: "*.log", Regex: `(?P<timestamp>\S+) (?P<event>\w+)`}); err != nil {
+		t.Fatalf("expected a valid regex to build, got %v", err)
+	}
+	if _, err := newRulesSource(SourceConfig{Name: "toggl", Glob: "*.log", Regex: `(?P<event>\w+)`}); err == nil {
+		t.Fatal("expected an error for a regex missing the timestamp group")
+	}
+	if _, err := newRulesSource(SourceConfig{Name: "toggl", Glob: "*.log", Regex: `(?P<timestamp>\S+)`}); err == nil {
+		t.Fatal("expected an error for a regex missing the event group")
+	}
+}
+
+func TestRulesSourceMatch(t *testing.T) {
+	src, err := newRulesSource(SourceConfig{
+		Name:        "toggl",
+		Glob:        "*.log",
+		Regex:       `^(?P<timestamp>[\d-]+T[\d:.]+) (?P<event>\w+)`,
+		TimeLayouts: []string{"2006-01-02T15:04:05"},
+	})
+	if err != nil {
+		t.Fatalf("newRulesSource: %v", err)
+	}
+
+	ev, ok := src.Match("2025-04-10T18:45:30 entry_started extra stuff")
+	if !ok {
+		t.Fatal("expected a match")
+	}
+	if ev.EventType != "entry_started" {
+		t.Fatalf("expected eventType entry_started, got %q", ev.EventType)
+	}
+	want := time.Date(2025, 4, 10, 18, 45, 30, 0, time.Local)
+	if !ev.Timestamp.Equal(want) {
+		t.Fatalf("expected timestamp %s, got %s", want, ev.Timestamp)
+	}
+}
+
+func TestRulesSourceLatestFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	older := filepath.Join(tmpDir, "toggl.1.log")
+	newer := filepath.Join(tmpDir, "toggl.2.log")
+	if err := os.WriteFile(older, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(newer, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	os.Chtimes(older, now, now.Add(-time.Hour))
+	os.Chtimes(newer, now, now)
+
+	src := &rulesSource{name: "toggl", dir: tmpDir, glob: "toggl.*.log"}
+	if got := src.LatestFile(tmpDir); got != newer {
+		t.Fatalf("expected %s, got %s", newer, got)
+	}
+}
+
+func TestBuildSourceUnknownType(t *testing.T) {
+	if _, err := buildSource(SourceConfig{Type: "bogus"}); err == nil {
+		t.Fatal("expected an error for an unknown source type")
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"syscall"
@@ -58,15 +59,68 @@ var (
 		},
 	}
 
-	// Connected clients – value == true  ➜  handshake completed
-	clients     = make(map[*websocket.Conn]bool)
-	clientsLock = sync.Mutex{}
-
-	// Connections that need to be closed by their reader goroutine
-	pendingCloses     = make(map[*websocket.Conn]bool)
-	pendingClosesLock = sync.Mutex{}
+	// connWG tracks live handleWebSocketMessages readers so shutdown can
+	// wait (up to ShutdownTimeout) for them to notice a close frame and
+	// exit, instead of cutting every connection off mid-write.
+	connWG sync.WaitGroup
 )
 
+// Client abstracts a transport-specific live connection so broadcastMessage
+// fans out to WebSocket and SSE subscribers identically.
+type Client interface {
+	Send(msg WSMessage) error
+	RemoteAddr() string
+	ConnID() string
+}
+
+// wsClient adapts a *websocket.Conn to Client, reusing the existing
+// per-connection write mutex so a handshake challenge and a concurrent
+// broadcast can never interleave on the wire.
+type wsClient struct {
+	conn   *websocket.Conn
+	connID string
+}
+
+func (c *wsClient) Send(msg WSMessage) error {
+	muIface, _ := writeMu.LoadOrStore(c.conn, &sync.Mutex{})
+	mu := muIface.(*sync.Mutex)
+	mu.Lock()
+	defer mu.Unlock()
+	return c.conn.WriteJSON(msg)
+}
+
+func (c *wsClient) RemoteAddr() string { return c.conn.RemoteAddr().String() }
+func (c *wsClient) ConnID() string     { return c.connID }
+
+// sseClient adapts an http.ResponseWriter streaming Server-Sent Events to
+// Client. Unlike wsClient it owns its mutex directly rather than sharing
+// the writeMu registry, since an SSE response has no separate close path
+// to clean that registry up from.
+type sseClient struct {
+	mu     sync.Mutex
+	w      http.ResponseWriter
+	flush  http.Flusher
+	addr   string
+	connID string
+}
+
+func (c *sseClient) Send(msg WSMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := fmt.Fprintf(c.w, "data: %s\n\n", body); err != nil {
+		return err
+	}
+	c.flush.Flush()
+	return nil
+}
+
+func (c *sseClient) RemoteAddr() string { return c.addr }
+func (c *sseClient) ConnID() string     { return c.connID }
+
 // Message types for WebSocket communication
 type WSMessage struct {
 	Type    string      `json:"type"`
@@ -74,22 +128,55 @@ type WSMessage struct {
 }
 
 type Config struct {
-	DebugMode     bool   `json:"debugMode"`
-	LogPath       string `json:"logPath"`
-	UpworkLogsDir string `json:"upworkLogsDir"`
-	WebSocketPort int    `json:"webSocketPort"`
+	DebugMode                  bool           `json:"debugMode"`
+	LogPath                    string         `json:"logPath"`
+	UpworkLogsDir              string         `json:"upworkLogsDir"`
+	WebSocketPort              int            `json:"webSocketPort"`
+	ShutdownTimeoutSeconds     int            `json:"shutdownTimeoutSeconds,omitempty"`
+	Sources                    []SourceConfig `json:"sources,omitempty"`
+	UpdateURL                  string         `json:"updateUrl,omitempty"`
+	UpdateChannel              string         `json:"updateChannel,omitempty"` // "stable" or "beta"
+	MetricsEnabled             bool           `json:"metricsEnabled,omitempty"`
+	MetricsAddr                string         `json:"metricsAddr,omitempty"`
+	LogLevel                   string         `json:"logLevel,omitempty"`  // "debug", "info", "warn" or "error"; defaults to "info"
+	LogFormat                  string         `json:"logFormat,omitempty"` // "text" or "json"; overrides --log-format when set
+	AdminEnabled               bool           `json:"adminEnabled,omitempty"`
+	AdminAddr                  string         `json:"adminAddr,omitempty"`
+	AdminToken                 string         `json:"adminToken,omitempty"`
+	AdminAllowRemote           bool           `json:"adminAllowRemote,omitempty"` // allow AdminAddr to bind somewhere other than loopback
+	GatewayEnabled             bool           `json:"gatewayEnabled,omitempty"`
+	GRPCPort                   int            `json:"grpcPort,omitempty"`                   // REST/JSON gateway port; see grpcgateway.go
+	DetectionLedgerPath        string         `json:"detectionLedgerPath,omitempty"`        // defaults to ~/.time-whisperer/detections.log
+	DetectionLedgerWindowHours int            `json:"detectionLedgerWindowHours,omitempty"` // dedup window; defaults to 24
 }
 
 func DefaultConfig() Config {
 	home, _ := os.UserHomeDir()
 	return Config{
-		DebugMode:     false,
-		LogPath:       filepath.Join(home, "time-whisperer.log"),
-		UpworkLogsDir: "", // Empty - will be discovered and filled in
-		WebSocketPort: 8887,
+		DebugMode:                  false,
+		LogPath:                    filepath.Join(home, "time-whisperer.log"),
+		UpworkLogsDir:              "", // Empty - will be discovered and filled in
+		WebSocketPort:              8887,
+		ShutdownTimeoutSeconds:     10,
+		UpdateChannel:              "stable",
+		LogLevel:                   "info",
+		AdminAddr:                  "127.0.0.1:8899",
+		GRPCPort:                   8898,
+		DetectionLedgerWindowHours: 24,
 	}
 }
 
+// shutdownTimeout returns how long main should wait for connected clients to
+// drain after a shutdown signal. A zero or unset ShutdownTimeoutSeconds
+// (e.g. an older config.json predating this field) falls back to 10s rather
+// than failing validation or shutting down with no grace period at all.
+func shutdownTimeout(cfg Config) time.Duration {
+	if cfg.ShutdownTimeoutSeconds <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(cfg.ShutdownTimeoutSeconds) * time.Second
+}
+
 // validateConfig validates configuration fields and returns true if valid
 func validateConfig(cfg Config) (bool, string) {
 	if cfg.LogPath == "" {
@@ -105,6 +192,47 @@ func validateConfig(cfg Config) (bool, string) {
 		return false, fmt.Sprintf("invalid webSocketPort: %d (must be between 1-65535)", cfg.WebSocketPort)
 	}
 
+	if cfg.ShutdownTimeoutSeconds < 0 {
+		return false, fmt.Sprintf("invalid shutdownTimeoutSeconds: %d (must be >= 0)", cfg.ShutdownTimeoutSeconds)
+	}
+
+	if cfg.UpdateChannel != "" && cfg.UpdateChannel != "stable" && cfg.UpdateChannel != "beta" {
+		return false, fmt.Sprintf("invalid updateChannel: %q (must be \"stable\" or \"beta\")", cfg.UpdateChannel)
+	}
+
+	if cfg.LogLevel != "" {
+		if _, ok := levelRank[logLevel(strings.ToLower(cfg.LogLevel))]; !ok {
+			return false, fmt.Sprintf("invalid logLevel: %q (must be \"debug\", \"info\", \"warn\" or \"error\")", cfg.LogLevel)
+		}
+	}
+
+	if cfg.LogFormat != "" && cfg.LogFormat != "text" && cfg.LogFormat != "json" {
+		return false, fmt.Sprintf("invalid logFormat: %q (must be \"text\" or \"json\")", cfg.LogFormat)
+	}
+
+	if cfg.AdminEnabled {
+		if cfg.AdminAddr == "" {
+			return false, "adminAddr cannot be empty when adminEnabled is true"
+		}
+		host, _, err := net.SplitHostPort(cfg.AdminAddr)
+		if err != nil {
+			return false, fmt.Sprintf("invalid adminAddr: %q (%v)", cfg.AdminAddr, err)
+		}
+		if !cfg.AdminAllowRemote && !isLoopbackHost(host) {
+			return false, fmt.Sprintf("invalid adminAddr: %q must be loopback unless adminAllowRemote is true", cfg.AdminAddr)
+		}
+	}
+
+	if cfg.GatewayEnabled {
+		if cfg.GRPCPort <= 0 || cfg.GRPCPort > 65535 {
+			return false, fmt.Sprintf("invalid grpcPort: %d (must be between 1-65535)", cfg.GRPCPort)
+		}
+	}
+
+	if cfg.DetectionLedgerWindowHours < 0 {
+		return false, fmt.Sprintf("invalid detectionLedgerWindowHours: %d (must be >= 0)", cfg.DetectionLedgerWindowHours)
+	}
+
 	// Expand tilde in log path if present
 	if strings.HasPrefix(cfg.LogPath, "~") {
 		home, err := os.UserHomeDir()
@@ -163,7 +291,7 @@ func discoverUpworkLogsDir() string {
 	}
 
 	var candidatePaths []string
-	
+
 	switch runtime.GOOS {
 	case "darwin":
 		candidatePaths = []string{
@@ -181,46 +309,46 @@ func discoverUpworkLogsDir() string {
 	}
 
 	for _, path := range candidatePaths {
-		log.Printf("Checking for Upwork logs in: %s", path)
-		
+		Debugf("config", "Checking for Upwork logs in: %s", path)
+
 		// Check if directory exists
 		if _, err := os.Stat(path); os.IsNotExist(err) {
-			log.Printf("Directory does not exist: %s", path)
+			Debugf("config", "Directory does not exist: %s", path)
 			continue
 		}
-		
+
 		// Check if directory contains upwork log files
 		pattern := filepath.Join(path, "upwork.*.log")
 		matches, err := filepath.Glob(pattern)
 		if err != nil {
-			log.Printf("Error checking for log files in %s: %v", path, err)
+			Warnf("config", "Error checking for log files in %s: %v", path, err)
 			continue
 		}
-		
+
 		if len(matches) > 0 {
-			log.Printf("Found %d upwork log file(s) in: %s", len(matches), path)
+			Infof("config", "Found %d upwork log file(s) in: %s", len(matches), path)
 			return path
 		} else {
-			log.Printf("No upwork log files found in: %s", path)
+			Debugf("config", "No upwork log files found in: %s", path)
 		}
 	}
-	
+
 	// Return empty string if no valid location found
-	log.Printf("No valid Upwork logs directory discovered")
+	Warnf("config", "No valid Upwork logs directory discovered")
 	return ""
 }
 
 // ensureUpworkLogsDir checks if UpworkLogsDir is empty and discovers it if needed
 func ensureUpworkLogsDir(cfg *Config) {
 	if cfg.UpworkLogsDir == "" {
-		log.Printf("UpworkLogsDir is empty, attempting to discover...")
+		Infof("config", "UpworkLogsDir is empty, attempting to discover...")
 		if discoveredPath := discoverUpworkLogsDir(); discoveredPath != "" {
 			cfg.UpworkLogsDir = discoveredPath
-			log.Printf("Discovered and set UpworkLogsDir: %s", discoveredPath)
+			Infof("config", "Discovered and set UpworkLogsDir: %s", discoveredPath)
 		} else {
 			// Fallback to platform default if discovery fails
 			cfg.UpworkLogsDir = getDefaultLogDir()
-			log.Printf("Discovery failed, using default UpworkLogsDir: %s", cfg.UpworkLogsDir)
+			Warnf("config", "Discovery failed, using default UpworkLogsDir: %s", cfg.UpworkLogsDir)
 		}
 	}
 }
@@ -287,10 +415,10 @@ func loadConfig(p string) (Config, string, error) {
 			if renameErr := os.Rename(p, bakPath); renameErr != nil {
 				return Config{}, "", fmt.Errorf("failed to back up invalid config: %w (original error: %v)", renameErr, err)
 			}
-			log.Printf("config: backed up invalid file to %s", bakPath)
+			Warnf("config", "backed up invalid file to %s", bakPath)
 			return Config{}, "", fmt.Errorf("invalid json: %w", err)
 		}
-		
+
 		// Ensure UpworkLogsDir is discovered if empty
 		originalDir := c.UpworkLogsDir
 		ensureUpworkLogsDir(&c)
@@ -298,7 +426,7 @@ func loadConfig(p string) (Config, string, error) {
 			// UpworkLogsDir was updated, save the improved config
 			_ = saveConfig(c, p)
 		}
-		
+
 		configSource = fmt.Sprintf("User config: %s", p)
 		return c, configSource, nil
 	} else if !os.IsNotExist(err) {
@@ -314,7 +442,7 @@ func loadConfig(p string) (Config, string, error) {
 			if err := json.Unmarshal(b, &c); err == nil {
 				// Ensure UpworkLogsDir is discovered if empty
 				ensureUpworkLogsDir(&c)
-				
+
 				// Save a copy to user config path
 				_ = saveConfig(c, p)
 				configSource = fmt.Sprintf("Bundled config: %s", bundledPath)
@@ -325,10 +453,10 @@ func loadConfig(p string) (Config, string, error) {
 
 	// Fallback to hardcoded defaults if no configs could be loaded
 	c := DefaultConfig()
-	
+
 	// Ensure UpworkLogsDir is discovered if empty
 	ensureUpworkLogsDir(&c)
-	
+
 	_ = saveConfig(c, p)
 	configSource = "Default hardcoded config (no config file found)"
 	return c, configSource, nil
@@ -340,17 +468,24 @@ func saveConfig(c Config, p string) error {
 	return os.WriteFile(p, d, 0o644)
 }
 
+// setDebugLogFlags toggles log.Lshortfile alongside date/time, matching
+// initLog's startup behavior. It's also used to apply a DebugMode change
+// pushed through the admin API without restarting the process.
+func setDebugLogFlags(debug bool) {
+	if debug {
+		log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
+	} else {
+		log.SetFlags(log.Ldate | log.Ltime)
+	}
+}
+
 func initLog(path string, debug bool) *os.File {
 	// Expand tilde in path if present
 	path = expandPath(path)
 
 	if path == "" {
 		log.SetOutput(os.Stdout)
-		if debug {
-			log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
-		} else {
-			log.SetFlags(log.Ldate | log.Ltime)
-		}
+		setDebugLogFlags(debug)
 		return nil
 	}
 	_ = os.MkdirAll(filepath.Dir(path), 0o755)
@@ -360,19 +495,17 @@ func initLog(path string, debug bool) *os.File {
 		return nil
 	}
 	log.SetOutput(io.MultiWriter(os.Stdout, f))
-	if debug {
-		log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
-	} else {
-		log.SetFlags(log.Ldate | log.Ltime)
-	}
+	setDebugLogFlags(debug)
 	return f
 }
 
-// startWebSocketServer starts the WebSocket server on the first available port
-func startWebSocketServer(ctx context.Context, mux *http.ServeMux) (int, error) {
+// startWebSocketServer starts the WebSocket server on the first available
+// port and registers it with mgr so a later mgr.Shutdown gracefully drains
+// it within the shutdown deadline instead of dropping connections outright.
+func startWebSocketServer(mux *http.ServeMux, mgr *Manager) (int, error) {
 	var lastErr error
 	for _, port := range candidatePorts {
-		log.Printf("Trying port %d for WebSocket server", port)
+		Debugf("ws", "Trying port %d for WebSocket server", port)
 		server := &http.Server{
 			Addr:    fmt.Sprintf(":%d", port),
 			Handler: mux,
@@ -380,7 +513,7 @@ func startWebSocketServer(ctx context.Context, mux *http.ServeMux) (int, error)
 
 		ln, err := net.Listen("tcp", server.Addr)
 		if err != nil {
-			log.Printf("Failed to bind port %d: %v", port, err)
+			Debugf("ws", "Failed to bind port %d: %v", port, err)
 			if strings.Contains(err.Error(), "address already in use") {
 				lastErr = err
 				continue // try next port
@@ -390,17 +523,15 @@ func startWebSocketServer(ctx context.Context, mux *http.ServeMux) (int, error)
 
 		go func() {
 			if err := server.Serve(ln); err != nil && err != http.ErrServerClosed {
-				log.Printf("WebSocket server error: %v", err)
+				Errorf("ws", "WebSocket server error: %v", err)
 			}
 		}()
 
-		go func() {
-			<-ctx.Done()
-			log.Println("Shutting down WebSocket server")
-			server.Shutdown(context.Background())
-		}()
+		mgr.Register("ws-server", func() error {
+			return server.Shutdown(context.Background())
+		})
 
-		log.Printf("Using port %d for WebSocket server", port)
+		Infof("ws", "Using port %d for WebSocket server", port)
 		return port, nil
 	}
 	return 0, fmt.Errorf("no free candidate port: %v", lastErr)
@@ -408,48 +539,71 @@ func startWebSocketServer(ctx context.Context, mux *http.ServeMux) (int, error)
 
 // handleWebSocket upgrades the HTTP request and starts the handshake.
 func handleWebSocket(w http.ResponseWriter, r *http.Request) {
-	log.Printf("New WebSocket connection attempt from %s", r.RemoteAddr)
+	if shuttingDown.Load() {
+		http.Error(w, "server shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
+	if shouldDropUpgrade() {
+		Warnf("ws", "test mode: dropping upgrade from %s", r.RemoteAddr)
+		http.Error(w, "simulated upgrade failure", http.StatusServiceUnavailable)
+		return
+	}
+
+	connID := nextConnID()
+	InfoFields("ws", "New WebSocket connection attempt", map[string]any{"conn_addr": r.RemoteAddr, "conn_id": connID})
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("upgrade failed: %v", err)
+		Warnf("ws", "upgrade failed: %v", err)
 		return
 	}
+	c := &wsClient{conn: conn, connID: connID}
 
 	// Mark as "not yet authenticated"
 	clientsLock.Lock()
-	clients[conn] = false
+	clients[c] = false
 	clientsLock.Unlock()
+	wsClientsGauge.Inc()
 
 	// Generate 16-byte random token
 	tokenBytes := make([]byte, 16)
 	if _, err := rand.Read(tokenBytes); err != nil {
-		log.Printf("rng: %v", err)
+		Errorf("ws", "rng: %v", err)
+
+		// We registered c and bumped wsClientsGauge above, but we're bailing
+		// out before handleWebSocketMessages starts, so its deferred cleanup
+		// never runs — undo both here ourselves.
+		clientsLock.Lock()
+		delete(clients, c)
+		clientsLock.Unlock()
+		wsClientsGauge.Dec()
+
 		conn.Close()
 		return
 	}
 	token := hex.EncodeToString(tokenBytes)
-	log.Printf("Generated authentication token for client %s", conn.RemoteAddr())
+	Debugf("ws", "Generated authentication token for client %s", conn.RemoteAddr())
 
 	// Send challenge
-	sendMessage(conn, WSMessage{
+	sendMessage(c, WSMessage{
 		Type: "hello",
 		Payload: map[string]any{
 			"token":   token,
 			"version": Version,
 		},
 	})
-	log.Printf("Sent hello challenge to client %s", conn.RemoteAddr())
+	Debugf("ws", "Sent hello challenge to client %s", conn.RemoteAddr())
 
 	// Abort if the extension never answers
 	timer := time.AfterFunc(5*time.Second, func() {
-		log.Printf("handshake timeout %s", conn.RemoteAddr())
+		Warnf("ws", "handshake timeout %s", conn.RemoteAddr())
 		// Send close message
 		conn.WriteMessage(websocket.CloseMessage,
 			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "handshake timeout"))
 
 		// Clean up resources - avoid leaking map entries and mutexes
 		clientsLock.Lock()
-		delete(clients, conn)
+		delete(clients, c)
 		clientsLock.Unlock()
 
 		// Also clean up the write mutex
@@ -458,18 +612,22 @@ func handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		// Finally close the connection
 		conn.Close()
 
-		log.Printf("Cleaned up resources for timed out connection %s", conn.RemoteAddr())
+		Debugf("ws", "Cleaned up resources for timed out connection %s", conn.RemoteAddr())
 	})
 
 	// Start reader
-	go handleWebSocketMessages(conn, token, timer)
+	go handleWebSocketMessages(conn, c, token, timer)
 }
 
 // handleWebSocketMessages handles incoming messages from WebSocket clients
-func handleWebSocketMessages(conn *websocket.Conn, expectedToken string, t *time.Timer) {
+func handleWebSocketMessages(conn *websocket.Conn, c Client, expectedToken string, t *time.Timer) {
+	connWG.Add(1)
+	defer connWG.Done()
+	defer wsClientsGauge.Dec()
+
 	defer func() {
 		clientsLock.Lock()
-		delete(clients, conn)
+		delete(clients, c)
 		clientCount := len(clients)
 		clientsLock.Unlock()
 
@@ -478,11 +636,11 @@ func handleWebSocketMessages(conn *websocket.Conn, expectedToken string, t *time
 
 		// Also clean up from pendingCloses if it's there
 		pendingClosesLock.Lock()
-		delete(pendingCloses, conn)
+		delete(pendingCloses, c)
 		pendingClosesLock.Unlock()
 
 		conn.Close()
-		log.Printf("WS client disconnected %s (remaining clients: %d)", conn.RemoteAddr(), clientCount)
+		InfoFields("ws", "WS client disconnected", map[string]any{"conn_addr": conn.RemoteAddr().String(), "conn_id": c.ConnID(), "remaining_clients": clientCount})
 	}()
 
 	authed := false
@@ -490,7 +648,7 @@ func handleWebSocketMessages(conn *websocket.Conn, expectedToken string, t *time
 	for {
 		// Check if this connection was marked for closing by sendMessage
 		pendingClosesLock.Lock()
-		shouldClose := pendingCloses[conn]
+		shouldClose := pendingCloses[c]
 		pendingClosesLock.Unlock()
 
 		if shouldClose {
@@ -503,7 +661,7 @@ func handleWebSocketMessages(conn *websocket.Conn, expectedToken string, t *time
 		if err := conn.ReadJSON(&msg); err != nil {
 			if websocket.IsUnexpectedCloseError(err,
 				websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("ws read: %v", err)
+				Warnf("ws", "ws read: %v", err)
 			}
 			return
 		}
@@ -512,13 +670,13 @@ func handleWebSocketMessages(conn *websocket.Conn, expectedToken string, t *time
 		// ────────────────── handshake path ──────────────────
 		if !authed {
 			if msg.Type != "hello_ack" {
-				log.Printf("Expected hello_ack but got %s from %s", msg.Type, conn.RemoteAddr())
+				Warnf("ws", "Expected hello_ack but got %s from %s", msg.Type, conn.RemoteAddr())
 				conn.Close()
 				return
 			}
 			pl, _ := msg.Payload.(map[string]any)
 			if tok, ok := pl["token"].(string); !ok || tok != expectedToken {
-				log.Printf("Invalid token from client %s", conn.RemoteAddr())
+				Warnf("ws", "Invalid token from client %s", conn.RemoteAddr())
 				conn.Close()
 				return
 			}
@@ -527,7 +685,7 @@ func handleWebSocketMessages(conn *websocket.Conn, expectedToken string, t *time
 			t.Stop()
 			authed = true
 			clientsLock.Lock()
-			clients[conn] = true
+			clients[c] = true
 			clientCount := 0
 			for _, auth := range clients {
 				if auth {
@@ -535,9 +693,9 @@ func handleWebSocketMessages(conn *websocket.Conn, expectedToken string, t *time
 				}
 			}
 			clientsLock.Unlock()
-			log.Printf("Authentication successful for client %s (authenticated clients: %d)", conn.RemoteAddr(), clientCount)
+			InfoFields("ws", "Authentication successful", map[string]any{"conn_addr": conn.RemoteAddr().String(), "conn_id": c.ConnID(), "authenticated_clients": clientCount})
 
-			sendMessage(conn, WSMessage{
+			sendMessage(c, WSMessage{
 				Type: "connected",
 				Payload: map[string]any{
 					"timestamp": time.Now().Format(time.RFC3339),
@@ -549,121 +707,210 @@ func handleWebSocketMessages(conn *websocket.Conn, expectedToken string, t *time
 		// ─────────── normal, post-handshake messages ───────────
 		switch msg.Type {
 		case "ping":
-			sendMessage(conn, WSMessage{
+			sendMessage(c, WSMessage{
 				Type: "pong",
 				Payload: map[string]any{
 					"timestamp": time.Now().Format(time.RFC3339),
 				},
 			})
+		case "subscribe":
+			handleSubscribe(c, msg)
 		default:
-			log.Printf("unknown msg %q from %s", msg.Type, conn.RemoteAddr())
+			Debugf("ws", "unknown msg %q from %s", msg.Type, conn.RemoteAddr())
 		}
 	}
 }
 
-// sendMessage writes a JSON frame to the client, serialising with any
-// concurrent broadcast via a per-connection mutex.
-func sendMessage(conn *websocket.Conn, msg WSMessage) {
-	muIface, _ := writeMu.LoadOrStore(conn, &sync.Mutex{})
-	mu := muIface.(*sync.Mutex)
-
-	mu.Lock()
-	err := conn.WriteJSON(msg)
-	mu.Unlock()
-
-	if err != nil {
-		log.Printf("write to %s failed: %v", conn.RemoteAddr(), err)
+// notifyEvent broadcasts a detection from any Source to all connected
+// clients. eventType "screenshot" keeps the original screenshot_detected
+// wire shape byte-for-byte (browser extensions and sse_test.go both match
+// on it); any other eventType is forwarded as a generic "event_detected"
+// frame carrying the source name and extra fields for newer consumers.
+func notifyEvent(sourceName, eventType string, timestamp time.Time, extra map[string]any) {
+	ev := Event{Source: sourceName, EventType: eventType, Timestamp: timestamp, Extra: extra}
+	if globalStore != nil {
+		if err := globalStore.Append(ev); err != nil {
+			Warnf("config", "event store: append failed: %v", err)
+		}
+	}
+	broadcastMessage(eventWSMessage(ev))
+}
 
-		// Signal to the reader goroutine that this connection should be closed
-		pendingClosesLock.Lock()
-		pendingCloses[conn] = true
-		pendingClosesLock.Unlock()
+// eventWSMessage renders a stored/live Event as the frame clients expect.
+// eventType "screenshot" keeps the original screenshot_detected wire shape
+// byte-for-byte (browser extensions and sse_test.go both match on it); any
+// other eventType is sent as a generic "event_detected" frame carrying the
+// source name and extra fields for newer consumers.
+func eventWSMessage(ev Event) WSMessage {
+	if ev.EventType == "screenshot" {
+		return WSMessage{
+			Type: "screenshot_detected",
+			Payload: map[string]any{
+				"timestamp": ev.Timestamp.Format("15:04:05"),
+				"time":      ev.Timestamp.Format(time.RFC3339),
+			},
+		}
+	}
 
-		// Remove from clients map but let the reader goroutine handle the actual close
-		clientsLock.Lock()
-		delete(clients, conn)
-		writeMu.Delete(conn)
-		clientsLock.Unlock()
+	payload := map[string]any{
+		"source":    ev.Source,
+		"eventType": ev.EventType,
+		"timestamp": ev.Timestamp.Format("15:04:05"),
+		"time":      ev.Timestamp.Format(time.RFC3339),
 	}
+	for k, v := range ev.Extra {
+		payload[k] = v
+	}
+	return WSMessage{Type: "event_detected", Payload: payload}
 }
 
-// broadcastMessage sends one JSON frame to every authenticated client,
-// re-using the same safe writer used by sendMessage.
-func broadcastMessage(msg WSMessage) {
-	clientsLock.Lock()
-	// Snapshot the targets while holding the lock.
-	targets := make([]*websocket.Conn, 0, len(clients))
-	for c, ok := range clients {
-		if ok { // only fully authenticated
-			targets = append(targets, c)
+// handleSubscribe replays every cached event newer than payload.since to c
+// before the connection switches to ordinary live broadcast, so an
+// extension that was closed for a while doesn't miss screenshots taken in
+// the meantime.
+//
+// c has been a live broadcastMessage target since the handshake completed
+// (not since subscribe), so without sealing the handoff a screenshot
+// detected while this replay is running would reach c twice: once live,
+// once from the store. replayBuffers (see pubsub.go) queues those live
+// frames for the duration of the replay instead of sending them
+// immediately; once the replay loop below finishes we flush the queue,
+// dropping anything the replay already covered.
+func handleSubscribe(c Client, msg WSMessage) {
+	if globalStore == nil {
+		return
+	}
+	pl, _ := msg.Payload.(map[string]any)
+	sinceStr, _ := pl["since"].(string)
+	since, err := time.Parse(time.RFC3339, sinceStr)
+	if err != nil {
+		Warnf("ws", "subscribe: invalid since %q from %s: %v", sinceStr, c.RemoteAddr(), err)
+		return
+	}
+
+	replayBuffersLock.Lock()
+	replayBuffers[c] = nil
+	replayBuffersLock.Unlock()
+
+	var lastReplayed time.Time
+	for _, ev := range globalStore.Since(since) {
+		sendMessage(c, eventWSMessage(ev))
+		if ev.Timestamp.After(lastReplayed) {
+			lastReplayed = ev.Timestamp
 		}
 	}
-	clientsLock.Unlock()
 
-	log.Printf("Broadcasting %q to %d client(s)", msg.Type, len(targets))
+	replayBuffersLock.Lock()
+	buffered := replayBuffers[c]
+	delete(replayBuffers, c)
+	replayBuffersLock.Unlock()
 
-	for _, c := range targets {
-		sendMessage(c, msg) // already mutex-protected
+	for _, m := range buffered {
+		if eventTimeCovered(m, lastReplayed) {
+			continue
+		}
+		sendMessage(c, m)
 	}
 }
 
-// notifyScreenshot sends a screenshot detection notification to all connected clients
-func notifyScreenshot(timestamp time.Time) {
-	broadcastMessage(WSMessage{
-		Type: "screenshot_detected",
-		Payload: map[string]any{
-			"timestamp": timestamp.Format("15:04:05"),
-			"time":      timestamp.Format(time.RFC3339),
-		},
-	})
+// eventTimeCovered reports whether m's "time" payload field falls at or
+// before lastReplayed, meaning the just-finished replay already delivered
+// it (or something no newer) and the buffered copy should be dropped rather
+// than sent a second time.
+func eventTimeCovered(m WSMessage, lastReplayed time.Time) bool {
+	if lastReplayed.IsZero() {
+		return false
+	}
+	pl, _ := m.Payload.(map[string]any)
+	ts, _ := pl["time"].(string)
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return false
+	}
+	return !t.After(lastReplayed)
 }
 
 const screenshotPattern = "Electron Screensnap succeeded"
 
-// getAllScreenshotTimestamps extracts screenshot timestamps from log file
-func getAllScreenshotTimestamps(logFile string) []string {
+// ScreenshotEvent is one detected "Electron Screensnap succeeded" line.
+// Log holds the base filename it was read from, and Raw the full
+// unmodified log line, so downstream tooling can consume it without
+// re-parsing.
+type ScreenshotEvent struct {
+	TS  time.Time `json:"ts"`
+	Log string    `json:"log"`
+	Raw string    `json:"raw"`
+}
+
+// getAllScreenshotTimestamps extracts screenshot events from logFile whose
+// time falls within [oldest, newest]. A zero time.Time for either bound
+// means that side is unbounded, so callers that want every event in the
+// file can just pass two zero values.
+func getAllScreenshotTimestamps(logFile string, oldest, newest time.Time) []ScreenshotEvent {
 	f, err := os.Open(logFile)
 	if err != nil {
 		return nil
 	}
 	defer f.Close()
 
-	var timestamps []string
+	logName := filepath.Base(logFile)
+	var events []ScreenshotEvent
 	sc := bufio.NewScanner(f)
 
 	for sc.Scan() {
 		line := sc.Text()
-		if strings.Contains(line, screenshotPattern) {
-			if ts := parseTS(line); !ts.IsZero() {
-				timestamps = append(timestamps, ts.Format(time.RFC3339Nano))
-			}
+		if !strings.Contains(line, screenshotPattern) {
+			continue
+		}
+		ts := parseTS(line)
+		if ts.IsZero() {
+			continue
+		}
+		if !oldest.IsZero() && ts.Before(oldest) {
+			continue
+		}
+		if !newest.IsZero() && ts.After(newest) {
+			continue
 		}
+		events = append(events, ScreenshotEvent{TS: ts, Log: logName, Raw: line})
 	}
-	return timestamps
+	return events
 }
 
-// lastScreenshotInfo returns the most‑recent screenshot timestamp and the full log line
-func lastScreenshotInfo(logFile string) (time.Time, string, error) {
+// lastScreenshotInfo returns the most recent screenshot event in logFile.
+func lastScreenshotInfo(logFile string) (ScreenshotEvent, error) {
 	f, err := os.Open(logFile)
 	if err != nil {
-		return time.Time{}, "", err
+		return ScreenshotEvent{}, err
 	}
 	defer f.Close()
 
-	var latest time.Time
-	var latestLine string
+	logName := filepath.Base(logFile)
+	var latest ScreenshotEvent
 	sc := bufio.NewScanner(f)
 
 	for sc.Scan() {
 		line := sc.Text()
 		if strings.Contains(line, screenshotPattern) {
-			if ts := parseTS(line); !ts.IsZero() && ts.After(latest) {
-				latest = ts
-				latestLine = line
+			if ts := parseTS(line); !ts.IsZero() && ts.After(latest.TS) {
+				latest = ScreenshotEvent{TS: ts, Log: logName, Raw: line}
 			}
 		}
 	}
-	return latest, latestLine, sc.Err()
+	return latest, sc.Err()
+}
+
+// emitJSON renders one JSON object per line, one per event, e.g.
+// {"ts":"2025-04-10T18:45:30.456Z","log":"upwork..20250410.log","raw":"..."}
+func emitJSON(w io.Writer, events []ScreenshotEvent) error {
+	enc := json.NewEncoder(w)
+	for _, e := range events {
+		rec := ScreenshotEvent{TS: e.TS.UTC(), Log: e.Log, Raw: e.Raw}
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // findLatestLog focuses only on upwork..*.log files which contain screenshot info
@@ -686,6 +933,59 @@ func findLatestLog(dir string) string {
 	return latest
 }
 
+// findAllLogs returns every upwork..*.log file in dir (same filter as
+// findLatestLog, excluding upwork.cmon.*), oldest modification time first,
+// so callers can walk rotated logs in chronological order.
+func findAllLogs(dir string) []string {
+	pattern := filepath.Join(expandPath(dir), "upwork.*.log")
+
+	matches, _ := filepath.Glob(pattern)
+	var files []string
+	for _, m := range matches {
+		if strings.Contains(filepath.Base(m), "upwork.cmon.") {
+			continue
+		}
+		files = append(files, m)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		fi, _ := os.Stat(files[i])
+		fj, _ := os.Stat(files[j])
+		return fi.ModTime().Before(fj.ModTime())
+	})
+	return files
+}
+
+// allScreenshotTimestampsAcrossLogs merges getAllScreenshotTimestamps across
+// every rotated log in dir, honoring the [since, until] window (both ends
+// inclusive, a zero bound unbounded) and skipping any log last modified
+// before since so we don't re-parse gigabytes of history on every
+// invocation, and de-duplicating timestamps that show up in more than one
+// file (e.g. because of a copy-truncate rotation overlap).
+func allScreenshotTimestampsAcrossLogs(dir string, since, until time.Time) []ScreenshotEvent {
+	seen := make(map[string]bool)
+	var merged []ScreenshotEvent
+
+	for _, f := range findAllLogs(dir) {
+		if !since.IsZero() {
+			if fi, err := os.Stat(f); err == nil && fi.ModTime().Before(since) {
+				continue // last written before the window opened
+			}
+		}
+		for _, e := range getAllScreenshotTimestamps(f, since, until) {
+			key := e.TS.Format(time.RFC3339Nano)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, e)
+		}
+	}
+
+	sort.Slice(merged, func(i, j int) bool { return merged[i].TS.Before(merged[j].TS) })
+	return merged
+}
+
 // parseTS returns the timestamp that sits inside the first [...] pair.
 // It accepts Upwork's "2025-05-12T11:26:23.318" (no zone) as well as the
 // full RFC 3339 variants. On failure it returns time.Time{}.
@@ -726,7 +1026,7 @@ func parseTS(line string) time.Time {
 func runMonitor(ctx context.Context, dir string) {
 	w, err := fsnotify.NewWatcher()
 	if err != nil {
-		log.Fatalf("fsnotify: %v", err)
+		Fatalf("fsnotify", "fsnotify: %v", err)
 	}
 	defer w.Close()
 
@@ -737,7 +1037,7 @@ func runMonitor(ctx context.Context, dir string) {
 
 	expandedDir := expandPath(dir)
 	if err := w.Add(expandedDir); err != nil {
-		log.Fatalf("watch %s: %v", expandedDir, err)
+		Fatalf("fsnotify", "watch %s: %v", expandedDir, err)
 	}
 
 	// ───────────────────────────────── current tail state ─────────────────────────────
@@ -775,13 +1075,14 @@ func runMonitor(ctx context.Context, dir string) {
 			return err
 		}
 		current, rdr = f, bufio.NewReaderSize(f, 64*1024)
-		log.Printf("Monitoring log file: %s", fname)
+		logReopensTotal.Inc()
+		Infof("monitor", "Monitoring log file: %s", fname)
 		return nil
 	}
 
 	// Perform initial opening of the log file.
 	if err := openCurrent(); err != nil {
-		log.Printf("Initial log open failed, will retry: %v", err)
+		Warnf("monitor", "Initial log open failed, will retry: %v", err)
 	}
 
 	prune := func(now time.Time) {
@@ -804,7 +1105,7 @@ func runMonitor(ctx context.Context, dir string) {
 	// Sat, Aug 2 2025 00:00 CEST. The timer will be set for ~23.5 hours.
 	duration := time.Until(nextMidnight())
 	timer := time.NewTimer(duration)
-	log.Printf("Scheduled next midnight log file check in %v", duration.Round(time.Second))
+	Debugf("monitor", "Scheduled next midnight log file check in %v", duration.Round(time.Second))
 	defer timer.Stop()
 
 	// ───────────────────────────────── event loop ────────────────────────────────────
@@ -817,9 +1118,9 @@ func runMonitor(ctx context.Context, dir string) {
 			return
 
 		case <-timer.C:
-			log.Printf("Midnight: Forcing a re-scan of the log directory.")
+			Infof("monitor", "Midnight: Forcing a re-scan of the log directory.")
 			if err := openCurrent(); err != nil {
-				log.Printf("Error during midnight log re-scan: %v", err)
+				Warnf("monitor", "Error during midnight log re-scan: %v", err)
 			}
 			// Use the defensive stop-then-reset pattern.
 			timer.Reset(time.Until(nextMidnight()))
@@ -828,9 +1129,9 @@ func runMonitor(ctx context.Context, dir string) {
 			// Watch for Create, Rename, and Remove to robustly handle rotation.
 			if ev.Op&(fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0 &&
 				strings.HasPrefix(filepath.Base(ev.Name), "upwork.") {
-				log.Printf("Filesystem event (%s on %s) triggered log re-scan.", ev.Op, filepath.Base(ev.Name))
+				Debugf("fsnotify", "Filesystem event (%s on %s) triggered log re-scan.", ev.Op, filepath.Base(ev.Name))
 				if err := openCurrent(); err != nil {
-					log.Printf("Error opening new log file after %s event: %v", ev.Op, err)
+					Warnf("monitor", "Error opening new log file after %s event: %v", ev.Op, err)
 				}
 			}
 
@@ -851,10 +1152,11 @@ func runMonitor(ctx context.Context, dir string) {
 				line, err := rdr.ReadString('\n')
 				if err != nil {
 					if err != io.EOF {
-						log.Printf("Read error on %s: %v", current.Name(), err)
+						logReadErrorsTotal.Inc()
+						Warnf("monitor", "Read error on %s: %v", current.Name(), err)
 						// Attempt to recover by reopening.
 						if openErr := openCurrent(); openErr != nil {
-							log.Printf("Error re-opening log after read error: %v", openErr)
+							Warnf("monitor", "Error re-opening log after read error: %v", openErr)
 						}
 					}
 					break
@@ -869,49 +1171,89 @@ func runMonitor(ctx context.Context, dir string) {
 					continue
 				}
 
-				key := ts.Format(time.RFC3339Nano)
-				if _, dup := seen[key]; dup {
+				// The detection ledger (when configured) is the durable source
+				// of truth for "have we reported this line before", since it
+				// survives restarts; the in-memory seen map below is only a
+				// same-process fallback for when it's disabled.
+				if globalLedger != nil && globalLedger.Seen(line) {
 					continue
 				}
-				seen[key] = entry{t: ts}
+
+				key := ts.Format(time.RFC3339Nano)
+				if globalLedger == nil {
+					if _, dup := seen[key]; dup {
+						continue
+					}
+					seen[key] = entry{t: ts}
+				}
 				lastSeen = ts
 				prune(ts)
 
-				log.Printf("Screenshot detected at %s", ts.Format("15:04:05"))
-				notifyScreenshot(ts)
+				if globalLedger != nil {
+					offset, _ := current.Seek(0, io.SeekCurrent)
+					if err := globalLedger.Record(line, current.Name(), offset, ts); err != nil {
+						Warnf("monitor", "detection ledger: record failed: %v", err)
+					}
+				}
+
+				screenshotsDetectedTotal.Inc()
+				lastScreenshotTimestampSeconds.Set(float64(ts.Unix()))
+				InfoFields("monitor", fmt.Sprintf("Screenshot detected at %s", ts.Format("15:04:05")), map[string]any{
+					"event":  "screenshot",
+					"ts":     ts.Format(time.RFC3339Nano),
+					"file":   filepath.Base(current.Name()),
+					"lag_ms": time.Since(ts).Milliseconds(),
+				})
+				notifyEvent("upwork", "screenshot", ts, nil)
 			}
 
 		case err := <-w.Errors:
-			log.Printf("watch error: %v", err)
+			Warnf("fsnotify", "watch error: %v", err)
 		}
 	}
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "find":
+			os.Exit(runFind(os.Args[2:]))
+		case "stats":
+			os.Exit(runStats(os.Args[2:]))
+		case "watch":
+			os.Exit(runWatch(os.Args[2:]))
+		}
+	}
+
 	log.SetOutput(os.Stdout)
 
 	showVer := flag.Bool("version", false, "print version and exit")
+	logFormatFlag := flag.String("log-format", "text", `log line format: "text" or "json"`)
+	checkUpdates := flag.Bool("check-updates", true, "check UpdateURL for a newer release on startup")
+	faults := flag.Bool("faults", false, "enable the /test/faults fault injector (also enabled by GO_TEST=1)")
 	flag.Parse()
 	if *showVer {
 		fmt.Printf("SneakTime %s (%s, %s)\n", Version, GitCommit, BuildDate)
 		return
 	}
+	logFormat = *logFormatFlag
+	faultsFlag = *faults
 
 	cfgPath := getConfigPath()
 	cfg, configSource, err := loadConfig(cfgPath)
 	if err != nil {
-		log.Fatalf("Unable to read config %s: %v", cfgPath, err)
+		Fatalf("config", "Unable to read config %s: %v", cfgPath, err)
 	}
 
 	// Log which config file is being used (convert to absolute path for clarity)
 	if absCfgPath, err := filepath.Abs(cfgPath); err == nil {
-		log.Printf("Config file path: %s", absCfgPath)
+		Infof("config", "Config file path: %s", absCfgPath)
 	} else {
-		log.Printf("Config file path: %s", cfgPath)
+		Infof("config", "Config file path: %s", cfgPath)
 	}
 
 	// Log full config
-	log.Printf("Loaded config: %+v", cfg)
+	Debugf("config", "Loaded config: %+v", cfg)
 
 	// Validate the config
 	if valid, errMsg := validateConfig(cfg); !valid {
@@ -926,6 +1268,21 @@ func main() {
 		os.Exit(1)
 	}
 
+	if cfg.LogLevel != "" {
+		if err := setLogLevel(cfg.LogLevel); err != nil {
+			Warnf("config", "logLevel: %v, keeping default", err)
+		}
+	}
+
+	// config.json's logFormat wins over --log-format's "text" default, so an
+	// operator doesn't have to touch the launchd/systemd unit just to switch
+	// a running daemon over to JSON for log shipping.
+	if cfg.LogFormat != "" {
+		logFormat = cfg.LogFormat
+	}
+
+	currentConfig.Store(&cfg)
+
 	logFile := initLog(cfg.LogPath, cfg.DebugMode)
 	if logFile != nil {
 		defer logFile.Close()
@@ -935,16 +1292,45 @@ func main() {
 	ctx, stop := context.WithCancel(context.Background())
 	defer stop()
 
+	// mgr coordinates graceful teardown of the components registered below
+	// (the WS server, the log file, the Upwork monitor) when a shutdown
+	// signal arrives; see Manager.Shutdown.
+	mgr := &Manager{}
+	if logFile != nil {
+		mgr.Register("log-file", logFile.Sync)
+	}
+
+	mgr.Register("config-watcher", watchConfigFile(ctx, cfgPath))
+
+	if store, err := newStore(""); err != nil {
+		Warnf("config", "event store disabled: %v", err)
+	} else {
+		globalStore = store
+		globalStore.StartPruner(ctx, 24*time.Hour, 30*24*time.Hour)
+	}
+
+	ledgerWindowHours := cfg.DetectionLedgerWindowHours
+	if ledgerWindowHours <= 0 {
+		ledgerWindowHours = 24
+	}
+	if ledger, err := newDetectionLedger(cfg.DetectionLedgerPath, time.Duration(ledgerWindowHours)*time.Hour, 10000); err != nil {
+		Warnf("config", "detection ledger disabled: %v", err)
+	} else {
+		globalLedger = ledger
+	}
+
 	// Log version information on startup
-	log.Printf("SneakTime %s (commit %s, built %s)", Version, GitCommit, BuildDate)
+	Infof("config", "SneakTime %s (commit %s, built %s)", Version, GitCommit, BuildDate)
 
 	// Log the config source
-	log.Printf("Using configuration from: %s", configSource)
+	Infof("config", "Using configuration from: %s", configSource)
 
-	log.Printf("Logs are also written to %s", cfg.LogPath)
+	Infof("config", "Logs are also written to %s", cfg.LogPath)
 
 	// Register handlers on the default mux
 	http.HandleFunc("/ws", handleWebSocket)
+	http.HandleFunc("/events", handleSSE)
+	http.HandleFunc("/events/send", handleEventsSend)
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
@@ -954,10 +1340,23 @@ func main() {
 			"timestamp": time.Now().Format(time.RFC3339),
 		})
 	})
+	registerMetrics(http.DefaultServeMux, false) // /metrics on the public mux, pprof never exposed here
+
+	if cfg.MetricsEnabled && cfg.MetricsAddr != "" {
+		go startMetricsListener(cfg.MetricsAddr, cfg.DebugMode)
+	}
+
+	if cfg.AdminEnabled && cfg.AdminAddr != "" {
+		go startAdminListener(cfg.AdminAddr, cfgPath)
+	}
+
+	if cfg.GatewayEnabled && cfg.GRPCPort != 0 {
+		go startGatewayListener(cfg.GRPCPort)
+	}
 
 	// Always register /test/broadcast in test mode for integration tests
 	if os.Getenv("GO_TEST") == "1" || cfg.DebugMode {
-		log.Printf("Debug mode: registering /test/broadcast handler")
+		Debugf("ws", "Debug mode: registering /test/broadcast handler")
 		http.HandleFunc("/test/broadcast", func(w http.ResponseWriter, r *http.Request) {
 			if r.Method != http.MethodPost {
 				w.WriteHeader(http.StatusMethodNotAllowed)
@@ -972,13 +1371,16 @@ func main() {
 			w.WriteHeader(http.StatusOK)
 		})
 	}
+	if faultsActive() || cfg.DebugMode {
+		registerFaultHandlers(http.DefaultServeMux)
+	}
 
 	// Start WebSocket server with deterministic port probing
-	chosenPort, err := startWebSocketServer(ctx, http.DefaultServeMux)
+	chosenPort, err := startWebSocketServer(http.DefaultServeMux, mgr)
 	if err != nil {
-		log.Fatalf("Failed to start WebSocket server: %v", err)
+		Fatalf("ws", "Failed to start WebSocket server: %v", err)
 	}
-	log.Printf("WebSocket server started on port %d", chosenPort)
+	Infof("ws", "WebSocket server started on port %d", chosenPort)
 
 	// Set up Upwork log monitoring
 	dir := cfg.UpworkLogsDir
@@ -986,21 +1388,113 @@ func main() {
 		dir = env
 	}
 	if dir == "" {
-		log.Fatalln("cannot determine Upwork log directory")
+		Fatalf("config", "cannot determine Upwork log directory")
 	}
 
-	log.Printf("Monitoring Upwork logs in %s", dir)
+	Infof("monitor", "Monitoring Upwork logs in %s", dir)
+
+	monitorSup := &monitorSupervisor{}
+	monitorSup.start(ctx, dir)
+	mgr.Register("monitor", monitorSup.closer())
+
+	// Observers the admin API's hot config reload feeds into: restart the
+	// tailer against a new directory, re-apply the log level/debug flags,
+	// all without dropping connected WS/SSE clients.
+	Subscribe(func(old, new *Config) {
+		if old == nil || old.UpworkLogsDir == new.UpworkLogsDir || new.UpworkLogsDir == "" {
+			return
+		}
+		Infof("config", "upworkLogsDir changed, restarting monitor: %s -> %s", old.UpworkLogsDir, new.UpworkLogsDir)
+		monitorSup.restart(ctx, new.UpworkLogsDir)
+	})
+	Subscribe(func(old, new *Config) {
+		if old == nil || old.LogLevel == new.LogLevel || new.LogLevel == "" {
+			return
+		}
+		if err := setLogLevel(new.LogLevel); err != nil {
+			Warnf("config", "admin API: %v", err)
+			return
+		}
+		Infof("config", "logLevel changed via admin API: %s -> %s", old.LogLevel, new.LogLevel)
+	})
+	Subscribe(func(old, new *Config) {
+		if old == nil || old.DebugMode == new.DebugMode {
+			return
+		}
+		setDebugLogFlags(new.DebugMode)
+		Infof("config", "debugMode changed via admin API: %v -> %v", old.DebugMode, new.DebugMode)
+	})
+	Subscribe(func(old, new *Config) {
+		if old == nil || old.LogFormat == new.LogFormat || new.LogFormat == "" {
+			return
+		}
+		logFormat = new.LogFormat
+		Infof("config", "logFormat changed via admin API: %s -> %s", old.LogFormat, new.LogFormat)
+	})
+	// No WS ping-interval observer: the server doesn't run a heartbeat
+	// timer today (clients drive "ping" themselves), so there's nothing
+	// for a config change to reconfigure yet.
+
+	if *checkUpdates && cfg.UpdateURL != "" {
+		go runUpdateCheck(cfg)
+	}
 
-	go runMonitor(ctx, dir)
+	// Launch any additional pluggable log sources (Toggl, Hubstaff, custom
+	// apps) configured in cfg.Sources alongside the primary Upwork tailer.
+	for _, sc := range cfg.Sources {
+		src, err := buildSource(sc)
+		if err != nil {
+			Errorf("config", "logsource %q: %v", sc.Name, err)
+			continue
+		}
+		go runGenericSource(ctx, src)
+	}
 
-	// Set up signal handling for graceful shutdown
+	// Set up signal handling: SIGINT/SIGTERM trigger graceful shutdown.
+	// SIGHUP does double duty, cycling the log verbosity (see cycleLogLevel)
+	// and re-reading cfgPath the same way watchConfigFile's fsnotify path
+	// does, so an operator without inotify (a container volume mount, an
+	// NFS-backed config dir) still has a way to force a reload.
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
-	<-sig
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
 
-	log.Println("shutting down")
-	stop() // Stop all background goroutines
+	var receivedSig os.Signal
+waitForShutdown:
+	for {
+		select {
+		case receivedSig = <-sig:
+			break waitForShutdown
+		case <-hup:
+			Infof("config", "SIGHUP received: log level now %q", cycleLogLevel())
+			reloadConfigFile(cfgPath)
+		}
+	}
+
+	Infof("config", "shutting down: received %s", receivedSig)
 
-	// Give time for graceful shutdown
-	time.Sleep(500 * time.Millisecond)
+	// Tell every connected client (via the server_shutdown frame and a
+	// close message) before tearing anything down, so the browser
+	// extension can tell a planned shutdown apart from a crash.
+	shutdownClients("server shutting down")
+
+	timeout := shutdownTimeout(cfg)
+	mgr.Register("clients", func() error {
+		if !waitForDrain(timeout) {
+			return fmt.Errorf("clients still connected after %v", timeout)
+		}
+		return nil
+	})
+
+	timedOut := mgr.Shutdown(timeout)
+	stop() // release anything else still tied to the root context (pluggable log sources, the store pruner)
+
+	if len(timedOut) > 0 {
+		Warnf("config", "shutdown: %d component(s) missed the %v deadline: %v", len(timedOut), timeout, timedOut)
+		if n := forceCloseClients(); n > 0 {
+			Warnf("ws", "shutdown: force-closed %d client connection(s) still open past the deadline", n)
+		}
+		os.Exit(1)
+	}
 }
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,9 +12,27 @@ import (
 
 const tsLayout = "2006-01-02T15:04:05.000"
 
-func equalRawTime(t time.Time, raw string) bool { return t.Format(tsLayout) == raw }
+// equalRawTime compares ts against the native-layout string raw by parsing
+// raw in ts's own zone and checking time.Time equality, rather than
+// formatting ts back to a string and comparing text.
+func equalRawTime(ts time.Time, raw string) bool {
+	want, err := time.ParseInLocation(tsLayout, raw, ts.Location())
+	if err != nil {
+		return false
+	}
+	return ts.Equal(want)
+}
 
-func hasPrefixIgnoringZone(s, raw string) bool { return strings.HasPrefix(s, raw) }
+// mustLocalTime parses the native Upwork layout in the local zone, for
+// comparing against ScreenshotEvent.TS in tests.
+func mustLocalTime(t *testing.T, raw string) time.Time {
+	t.Helper()
+	ts, err := time.ParseInLocation(tsLayout, raw, time.Local)
+	if err != nil {
+		t.Fatalf("bad test timestamp %q: %v", raw, err)
+	}
+	return ts
+}
 
 func TestFindLatestLog(t *testing.T) {
 	tmp, err := os.MkdirTemp("", "tw-*")
@@ -55,15 +75,18 @@ func TestLastScreenshotInfo(t *testing.T) {
 `)
 	f.Close()
 
-	ts, line, err := lastScreenshotInfo(f.Name())
+	ev, err := lastScreenshotInfo(f.Name())
 	if err != nil {
 		t.Fatal(err)
 	}
-	if !equalRawTime(ts, "2025-04-10T18:45:30.456") {
-		t.Fatalf("timestamp mismatch: %s", ts.Format(tsLayout))
+	if !equalRawTime(ev.TS, "2025-04-10T18:45:30.456") {
+		t.Fatalf("timestamp mismatch: %s", ev.TS.Format(tsLayout))
 	}
-	if !strings.Contains(line, "18:45:30.456") {
-		t.Fatalf("unexpected line: %q", line)
+	if !strings.Contains(ev.Raw, "18:45:30.456") {
+		t.Fatalf("unexpected line: %q", ev.Raw)
+	}
+	if ev.Log != filepath.Base(f.Name()) {
+		t.Fatalf("log mismatch: %s", ev.Log)
 	}
 }
 
@@ -80,7 +103,7 @@ func TestGetAllScreenshotTimestamps(t *testing.T) {
 `)
 	f.Close()
 
-	got := getAllScreenshotTimestamps(f.Name())
+	got := getAllScreenshotTimestamps(f.Name(), time.Time{}, time.Time{})
 	want := []string{
 		"2025-04-10T10:30:45.123",
 		"2025-04-10T12:45:30.456",
@@ -90,8 +113,130 @@ func TestGetAllScreenshotTimestamps(t *testing.T) {
 		t.Fatalf("expected 3, got %d", len(got))
 	}
 	for i := range want {
-		if i >= len(got) || !hasPrefixIgnoringZone(got[i], want[i]) {
-			t.Fatalf("timestamp[%d] = %s, want prefix %s", i, got[i], want[i])
+		if !equalRawTime(got[i].TS, want[i]) {
+			t.Fatalf("timestamp[%d] = %s, want %s", i, got[i].TS, want[i])
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// parseTS ----------------------------------------------------------------------
+func TestParseTSWithZoneOffset(t *testing.T) {
+	cases := []struct {
+		line    string
+		wantOff int
+	}{
+		{"[2025-04-10T18:45:30.456+09:00] [INFO] Electron Screensnap succeeded.", 9 * 3600},
+		{"[2025-04-10T18:45:30.456Z] [INFO] Electron Screensnap succeeded.", 0},
+	}
+	for _, c := range cases {
+		ts := parseTS(c.line)
+		if ts.IsZero() {
+			t.Fatalf("failed to parse %q", c.line)
+		}
+		if _, off := ts.Zone(); off != c.wantOff {
+			t.Fatalf("%q: got offset %d, want %d", c.line, off, c.wantOff)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// findAllLogs / allScreenshotTimestampsAcrossLogs ------------------------------
+func writeRotatedLog(t *testing.T, dir, name string, mod time.Time, lines ...string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	content := strings.Join(lines, "\n") + "\n"
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(p, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestEmitJSON(t *testing.T) {
+	ev := ScreenshotEvent{
+		TS:  mustLocalTime(t, "2025-04-10T18:45:30.456"),
+		Log: "upwork..20250410.log",
+		Raw: "[2025-04-10T18:45:30.456] [INFO] main.shell.os_services - Electron Screensnap succeeded.",
+	}
+
+	var buf bytes.Buffer
+	if err := emitJSON(&buf, []ScreenshotEvent{ev}); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded ScreenshotEvent
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid json line: %v\n%s", err, buf.String())
+	}
+	if !decoded.TS.Equal(ev.TS) {
+		t.Fatalf("ts mismatch: %s vs %s", decoded.TS, ev.TS)
+	}
+	if decoded.Log != ev.Log || decoded.Raw != ev.Raw {
+		t.Fatalf("got %+v, want %+v", decoded, ev)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(buf.String()), "}") {
+		t.Fatalf("expected one JSON object per line, got: %s", buf.String())
+	}
+}
+
+func TestFindAllLogs(t *testing.T) {
+	tmp, err := os.MkdirTemp("", "tw-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	base := time.Now().Add(-72 * time.Hour)
+	writeRotatedLog(t, tmp, "upwork..20250410.log", base, "x")
+	writeRotatedLog(t, tmp, "upwork..20250411.log", base.Add(24*time.Hour), "x")
+	writeRotatedLog(t, tmp, "upwork..20250412.log", base.Add(48*time.Hour), "x")
+	writeRotatedLog(t, tmp, "upwork.cmon.20250412.log", base.Add(48*time.Hour), "x") // ignored
+
+	got := findAllLogs(tmp)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 logs, got %d: %v", len(got), got)
+	}
+	want := []string{"upwork..20250410.log", "upwork..20250411.log", "upwork..20250412.log"}
+	for i, w := range want {
+		if filepath.Base(got[i]) != w {
+			t.Fatalf("logs[%d] = %s, want %s", i, filepath.Base(got[i]), w)
+		}
+	}
+}
+
+func TestAllScreenshotTimestampsAcrossLogs(t *testing.T) {
+	tmp, err := os.MkdirTemp("", "tw-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	base := time.Now().Add(-72 * time.Hour)
+	writeRotatedLog(t, tmp, "upwork..20250410.log", base,
+		"[2025-04-10T10:00:00.000] [INFO] main.shell.os_services - Electron Screensnap succeeded.",
+		"[2025-04-10T23:59:00.000] [INFO] main.shell.os_services - Electron Screensnap succeeded.")
+	// overlapping rotation: the 23:59 event is duplicated at the top of the
+	// next day's file, as can happen around midnight rollover.
+	writeRotatedLog(t, tmp, "upwork..20250411.log", base.Add(24*time.Hour),
+		"[2025-04-10T23:59:00.000] [INFO] main.shell.os_services - Electron Screensnap succeeded.",
+		"[2025-04-11T08:00:00.000] [INFO] main.shell.os_services - Electron Screensnap succeeded.")
+	writeRotatedLog(t, tmp, "upwork..20250412.log", base.Add(48*time.Hour),
+		"[2025-04-12T09:00:00.000] [INFO] main.shell.os_services - Electron Screensnap succeeded.")
+
+	since, _ := time.ParseInLocation(tsLayout, "2025-04-10T00:00:00.000", time.Local)
+	until, _ := time.ParseInLocation(tsLayout, "2025-04-11T23:59:59.000", time.Local)
+
+	got := allScreenshotTimestampsAcrossLogs(tmp, since, until)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 de-duplicated timestamps, got %d: %v", len(got), got)
+	}
+	want := []string{"2025-04-10T10:00:00.000", "2025-04-10T23:59:00.000", "2025-04-11T08:00:00.000"}
+	for i, w := range want {
+		if !equalRawTime(got[i].TS, w) {
+			t.Fatalf("timestamp[%d] = %s, want %s", i, got[i].TS, w)
 		}
 	}
 }
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	screenshotsDetectedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "sneaktime_screenshots_detected_total",
+		Help: "Total number of screenshot detections across all monitored sources.",
+	})
+	logReadErrorsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "sneaktime_log_read_errors_total",
+		Help: "Total number of errors reading the currently-tailed log file.",
+	})
+	logReopensTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "sneaktime_log_reopens_total",
+		Help: "Total number of times the tailer opened a (new or recovered) log file.",
+	})
+	wsClientsGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "sneaktime_ws_clients",
+		Help: "Current number of connected WebSocket/SSE clients (authenticated or not).",
+	})
+	wsBroadcastTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sneaktime_ws_broadcast_total",
+		Help: "Total number of per-client message sends, labeled by result.",
+	}, []string{"result"})
+	wsBroadcastLatencySeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "sneaktime_ws_broadcast_latency_seconds",
+		Help:    "Latency of broadcastMessage fanning a single frame out to every client.",
+		Buckets: prometheus.DefBuckets,
+	})
+	lastScreenshotTimestampSeconds = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "sneaktime_last_screenshot_timestamp_seconds",
+		Help: "Unix timestamp of the most recently detected screenshot.",
+	})
+)
+
+func init() {
+	// Not registering a Go/process collector here: the client_golang
+	// version this repo builds against already registers both into
+	// prometheus.DefaultRegisterer on import, and a second registration of
+	// the same collector names panics via MustRegister.
+	prometheus.MustRegister(
+		screenshotsDetectedTotal,
+		logReadErrorsTotal,
+		logReopensTotal,
+		wsClientsGauge,
+		wsBroadcastTotal,
+		wsBroadcastLatencySeconds,
+		lastScreenshotTimestampSeconds,
+	)
+}
+
+// registerMetrics wires /metrics (and, in debug mode, net/http/pprof) onto
+// mux. It's called both for the main request mux (so /metrics sits next to
+// /health) and, when MetricsAddr is configured, for a second, operator-only
+// listener isolated from the public WS/SSE port.
+func registerMetrics(mux *http.ServeMux, debugMode bool) {
+	mux.Handle("/metrics", promhttp.Handler())
+
+	if debugMode {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+}
+
+// startMetricsListener starts a standalone monitoring listener bound to
+// addr, separate from the public WS/SSE port, so /metrics and pprof never
+// have to be exposed on the same listener browser extensions talk to.
+func startMetricsListener(addr string, debugMode bool) {
+	mux := http.NewServeMux()
+	registerMetrics(mux, debugMode)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		Errorf("config", "metrics listener on %s failed: %v", addr, err)
+	}
+}
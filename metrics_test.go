@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegisterMetricsServesMetrics(t *testing.T) {
+	mux := http.NewServeMux()
+	registerMetrics(mux, false)
+
+	screenshotsDetectedTotal.Inc()
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 from /metrics, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "sneaktime_screenshots_detected_total") {
+		t.Fatalf("expected sneaktime_screenshots_detected_total in output, got: %s", rec.Body.String())
+	}
+}
+
+func TestRegisterMetricsOmitsPprofByDefault(t *testing.T) {
+	mux := http.NewServeMux()
+	registerMetrics(mux, false)
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected pprof to be unregistered without debug mode, got status %d", rec.Code)
+	}
+}
+
+func TestRegisterMetricsIncludesPprofInDebugMode(t *testing.T) {
+	mux := http.NewServeMux()
+	registerMetrics(mux, true)
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code == http.StatusNotFound {
+		t.Fatalf("expected pprof to be registered in debug mode")
+	}
+}
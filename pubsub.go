@@ -0,0 +1,102 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// This file holds the in-process fan-out used by every live connection,
+// WebSocket or SSE: the client registry, the pending-close bookkeeping
+// sendMessage relies on, and broadcastMessage itself. handleWebSocket,
+// handleSSE, shutdownClients and (as of this file) the REST gateway in
+// grpcgateway.go all publish and subscribe through the same maps, so a
+// screenshot detected once reaches every transport identically.
+var (
+	// Connected clients (WebSocket, SSE, or gateway) – value == true ➜
+	// handshake completed (SSE and gateway clients skip the handshake and
+	// are registered already-authenticated).
+	clients     = make(map[Client]bool)
+	clientsLock = sync.Mutex{}
+
+	// Connections that need to be closed by their reader goroutine
+	pendingCloses     = make(map[Client]bool)
+	pendingClosesLock = sync.Mutex{}
+
+	// Clients currently mid-handleSubscribe replay. While a client has an
+	// entry here, broadcastMessage queues frames for it instead of sending
+	// them immediately, so a screenshot detected during the replay handoff
+	// is delivered once, after the replay, rather than racing it (see
+	// bufferLiveMessage and handleSubscribe).
+	replayBuffers     = make(map[Client][]WSMessage)
+	replayBuffersLock = sync.Mutex{}
+)
+
+// sendMessage writes a frame to the client, marking it for close (and
+// dropping it from the client registry) if the write fails.
+func sendMessage(c Client, msg WSMessage) {
+	if ws, ok := c.(*wsClient); ok && shouldCloseOnBroadcast() {
+		Warnf("ws", "test mode: forcing close on %s mid-broadcast", c.RemoteAddr())
+		ws.conn.Close()
+	}
+
+	if err := c.Send(msg); err != nil {
+		wsBroadcastTotal.WithLabelValues("drop").Inc()
+		Warnf("ws", "write to %s failed: %v", c.RemoteAddr(), err)
+
+		// Signal to the reader goroutine that this connection should be closed
+		pendingClosesLock.Lock()
+		pendingCloses[c] = true
+		pendingClosesLock.Unlock()
+
+		// Remove from clients map but let the reader goroutine handle the actual close
+		clientsLock.Lock()
+		delete(clients, c)
+		clientsLock.Unlock()
+		return
+	}
+	wsBroadcastTotal.WithLabelValues("ok").Inc()
+}
+
+// broadcastMessage sends one frame to every authenticated client, WebSocket,
+// SSE or gateway alike, re-using the same safe writer used by sendMessage.
+func broadcastMessage(msg WSMessage) {
+	timer := prometheus.NewTimer(wsBroadcastLatencySeconds)
+	defer timer.ObserveDuration()
+
+	clientsLock.Lock()
+	// Snapshot the targets while holding the lock.
+	targets := make([]Client, 0, len(clients))
+	for c, ok := range clients {
+		if ok { // only fully authenticated
+			targets = append(targets, c)
+		}
+	}
+	clientsLock.Unlock()
+
+	Debugf("broadcast", "Broadcasting %q to %d client(s)", msg.Type, len(targets))
+
+	for _, c := range targets {
+		if bufferLiveMessage(c, msg) {
+			continue
+		}
+		sendMessage(c, msg) // already mutex-protected
+	}
+}
+
+// bufferLiveMessage queues msg for c instead of delivering it immediately if
+// c is currently mid-handleSubscribe replay, and reports whether it did so.
+// Without this, a client that reconnects and sends "subscribe" can receive a
+// screenshot both live (it's already a broadcast target at handshake) and
+// again via replay (the store has it too), with the replay arriving after
+// the live frame it duplicates.
+func bufferLiveMessage(c Client, msg WSMessage) bool {
+	replayBuffersLock.Lock()
+	defer replayBuffersLock.Unlock()
+	buf, replaying := replayBuffers[c]
+	if !replaying {
+		return false
+	}
+	replayBuffers[c] = append(buf, msg)
+	return true
+}
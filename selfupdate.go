@@ -0,0 +1,237 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// updatePublicKey verifies the signature on every fetched manifest. It's a
+// build-time placeholder; real releases are expected to inject the actual
+// release-signing public key at build time the same way Version/GitCommit
+// are, via -ldflags. It must stay exactly 64 hex chars (32 bytes, the
+// ed25519 public key size) so ed25519.Verify never panics on a bad key
+// length. fetchManifest refuses the all-zero placeholder before calling
+// Verify: all zeros encodes a small-order point, which Verify accepts for
+// some forged signatures.
+var updatePublicKeyHex = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// Manifest describes the latest available release, as published at
+// Config.UpdateURL.
+type Manifest struct {
+	Version string `json:"version"`
+	Channel string `json:"channel"`
+	URL     string `json:"url"`
+	SHA256  string `json:"sha256"`
+	Notes   string `json:"notes"`
+}
+
+// signedManifest is the actual document fetched from UpdateURL: a raw
+// Manifest JSON blob plus a detached ed25519 signature (hex) over those
+// exact bytes, so the manifest can be re-signed without touching this
+// struct's shape.
+type signedManifest struct {
+	Manifest  json.RawMessage `json:"manifest"`
+	Signature string          `json:"signature"`
+}
+
+// fetchManifest downloads and ed25519-verifies the manifest at url.
+func fetchManifest(url string) (*Manifest, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("selfupdate: fetch manifest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("selfupdate: fetch manifest: unexpected status %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("selfupdate: read manifest: %w", err)
+	}
+
+	var sm signedManifest
+	if err := json.Unmarshal(body, &sm); err != nil {
+		return nil, fmt.Errorf("selfupdate: decode manifest envelope: %w", err)
+	}
+
+	sig, err := hex.DecodeString(sm.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("selfupdate: decode signature: %w", err)
+	}
+	if strings.Trim(updatePublicKeyHex, "0") == "" {
+		return nil, fmt.Errorf("selfupdate: no release-signing public key configured")
+	}
+	pubKey, err := hex.DecodeString(updatePublicKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("selfupdate: decode public key: %w", err)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(pubKey), sm.Manifest, sig) {
+		return nil, fmt.Errorf("selfupdate: manifest signature verification failed")
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(sm.Manifest, &m); err != nil {
+		return nil, fmt.Errorf("selfupdate: decode manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// compareVersions returns -1, 0, or 1 as dotted version a is less than,
+// equal to, or greater than b. Non-numeric or missing components compare
+// as 0, so "1.2" < "1.2.1" and a malformed version never panics.
+func compareVersions(a, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var an, bn int
+		if i < len(as) {
+			an, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			bn, _ = strconv.Atoi(bs[i])
+		}
+		if an != bn {
+			if an < bn {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// isAppBundle reports whether the running executable lives inside a macOS
+// .app bundle (i.e. Foo.app/Contents/MacOS/time-whisperer), the same
+// bundle layout getBundledConfigPath already special-cases.
+func isAppBundle() bool {
+	execPath, err := os.Executable()
+	if err != nil {
+		return false
+	}
+	return strings.Contains(filepath.ToSlash(execPath), ".app/Contents/")
+}
+
+// updateChannel returns cfg.UpdateChannel, defaulting to "stable" the same
+// way DefaultConfig does, so an older config.json that predates the field
+// behaves like an explicit "stable" rather than matching every channel.
+func updateChannel(cfg Config) string {
+	if cfg.UpdateChannel == "" {
+		return "stable"
+	}
+	return cfg.UpdateChannel
+}
+
+// checkForUpdate fetches cfg.UpdateURL and returns the manifest if it's on
+// cfg.UpdateChannel and describes a version newer than Version, or nil if
+// already current or on a different channel. A manifest with no Channel set
+// is treated as "stable", matching updateChannel's own default.
+func checkForUpdate(cfg Config) (*Manifest, error) {
+	if cfg.UpdateURL == "" {
+		return nil, nil
+	}
+	m, err := fetchManifest(cfg.UpdateURL)
+	if err != nil {
+		return nil, err
+	}
+	wantChannel := updateChannel(cfg)
+	gotChannel := m.Channel
+	if gotChannel == "" {
+		gotChannel = "stable"
+	}
+	if gotChannel != wantChannel {
+		Debugf("config", "update check: ignoring %s manifest (version %s), on %q channel", gotChannel, m.Version, wantChannel)
+		return nil, nil
+	}
+	if compareVersions(Version, m.Version) >= 0 {
+		return nil, nil
+	}
+	return m, nil
+}
+
+// runUpdateCheck fetches cfg.UpdateURL once and, if a newer release is
+// published, warns the log and broadcasts an update_available frame so the
+// Chrome extension can surface it to the user. It never calls applyUpdate
+// itself; replacing the running binary stays an explicit, separate step.
+func runUpdateCheck(cfg Config) {
+	m, err := checkForUpdate(cfg)
+	if err != nil {
+		Warnf("config", "update check failed: %v", err)
+		return
+	}
+	if m == nil {
+		Infof("config", "up to date (version %s)", Version)
+		return
+	}
+
+	Warnf("config", "update available: %s -> %s (%s)", Version, m.Version, m.URL)
+	broadcastMessage(WSMessage{
+		Type: "update_available",
+		Payload: map[string]any{
+			"version": m.Version,
+			"url":     m.URL,
+			"sha256":  m.SHA256,
+			"notes":   m.Notes,
+		},
+	})
+}
+
+// applyUpdate downloads the release at m.URL, verifies its SHA-256 against
+// m.SHA256, and atomically replaces the running executable. It refuses to
+// run from inside a macOS .app bundle, where replacing the raw binary
+// would leave the bundle's code signature (and Gatekeeper) broken.
+func applyUpdate(m *Manifest) error {
+	if isAppBundle() {
+		return fmt.Errorf("selfupdate: refusing to self-update from inside a .app bundle")
+	}
+
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("selfupdate: locate running executable: %w", err)
+	}
+
+	resp, err := http.Get(m.URL)
+	if err != nil {
+		return fmt.Errorf("selfupdate: download: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("selfupdate: download: unexpected status %s", resp.Status)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(execPath), "time-whisperer-update-*")
+	if err != nil {
+		return fmt.Errorf("selfupdate: create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once the rename below succeeds
+
+	hasher := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hasher), resp.Body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("selfupdate: write temp file: %w", err)
+	}
+	tmp.Close()
+
+	if got := hex.EncodeToString(hasher.Sum(nil)); got != m.SHA256 {
+		return fmt.Errorf("selfupdate: sha256 mismatch: got %s, want %s", got, m.SHA256)
+	}
+
+	if err := os.Chmod(tmpPath, 0o755); err != nil {
+		return fmt.Errorf("selfupdate: chmod: %w", err)
+	}
+	if err := os.Rename(tmpPath, execPath); err != nil {
+		return fmt.Errorf("selfupdate: replace executable: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.0", "1.0.1", -1},
+		{"1.2.0", "1.1.9", 1},
+		{"1.2", "1.2.1", -1},
+		{"2.0.0", "1.9.9", 1},
+	}
+	for _, c := range cases {
+		if got := compareVersions(c.a, c.b); got != c.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestCheckForUpdateNoURLConfigured(t *testing.T) {
+	m, err := checkForUpdate(Config{})
+	if err != nil || m != nil {
+		t.Fatalf("expected (nil, nil) with no UpdateURL configured, got (%+v, %v)", m, err)
+	}
+}
+
+// serveSignedManifest spins up an httptest.Server returning m signed with a
+// freshly generated ed25519 key, and points updatePublicKeyHex at the
+// matching public key for the duration of the test.
+func serveSignedManifest(t *testing.T, m Manifest) *httptest.Server {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	orig := updatePublicKeyHex
+	updatePublicKeyHex = hex.EncodeToString(pub)
+	t.Cleanup(func() { updatePublicKeyHex = orig })
+
+	raw, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("marshal manifest: %v", err)
+	}
+	sig := ed25519.Sign(priv, raw)
+
+	body, err := json.Marshal(signedManifest{
+		Manifest:  raw,
+		Signature: hex.EncodeToString(sig),
+	})
+	if err != nil {
+		t.Fatalf("marshal envelope: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchManifestAcceptsValidSignature(t *testing.T) {
+	want := Manifest{Version: "9.9.9", Channel: "stable", URL: "https://example.invalid/bin", SHA256: "abc"}
+	srv := serveSignedManifest(t, want)
+
+	got, err := fetchManifest(srv.URL)
+	if err != nil {
+		t.Fatalf("fetchManifest: %v", err)
+	}
+	if *got != want {
+		t.Fatalf("fetchManifest = %+v, want %+v", *got, want)
+	}
+}
+
+func TestFetchManifestRejectsTamperedSignature(t *testing.T) {
+	srv := serveSignedManifest(t, Manifest{Version: "9.9.9"})
+
+	// Swap in an unrelated public key after the server (and its signature)
+	// are already fixed, simulating the build-time placeholder: Verify must
+	// return an error, not panic, even though the key is a valid 32 bytes.
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	updatePublicKeyHex = hex.EncodeToString(otherPub)
+
+	if _, err := fetchManifest(srv.URL); err == nil {
+		t.Fatal("expected a signature mismatch to be rejected")
+	}
+}
+
+func TestCheckForUpdateIgnoresManifestOnOtherChannel(t *testing.T) {
+	srv := serveSignedManifest(t, Manifest{Version: "9.9.9", Channel: "beta", URL: "https://example.invalid/bin"})
+
+	m, err := checkForUpdate(Config{UpdateURL: srv.URL, UpdateChannel: "stable"})
+	if err != nil {
+		t.Fatalf("checkForUpdate: %v", err)
+	}
+	if m != nil {
+		t.Fatalf("expected a beta manifest to be ignored on the stable channel, got %+v", m)
+	}
+}
+
+func TestCheckForUpdateMatchesConfiguredChannel(t *testing.T) {
+	srv := serveSignedManifest(t, Manifest{Version: "9.9.9", Channel: "beta", URL: "https://example.invalid/bin"})
+
+	m, err := checkForUpdate(Config{UpdateURL: srv.URL, UpdateChannel: "beta"})
+	if err != nil {
+		t.Fatalf("checkForUpdate: %v", err)
+	}
+	if m == nil {
+		t.Fatal("expected a beta manifest to be offered on the beta channel")
+	}
+}
+
+func TestCheckForUpdateTreatsUnsetChannelAsStable(t *testing.T) {
+	srv := serveSignedManifest(t, Manifest{Version: "9.9.9", URL: "https://example.invalid/bin"})
+
+	m, err := checkForUpdate(Config{UpdateURL: srv.URL})
+	if err != nil {
+		t.Fatalf("checkForUpdate: %v", err)
+	}
+	if m == nil {
+		t.Fatal("expected a channel-less manifest to be offered to a default (stable) config")
+	}
+}
+
+func TestFetchManifestPlaceholderKeyFailsClosedWithoutPanicking(t *testing.T) {
+	want := Manifest{Version: "9.9.9"}
+	raw, _ := json.Marshal(want)
+	body, _ := json.Marshal(signedManifest{Manifest: raw, Signature: hex.EncodeToString(make([]byte, ed25519.SignatureSize))})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	if _, err := fetchManifest(srv.URL); err == nil {
+		t.Fatal("expected the build-time placeholder key to reject every signature")
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// shuttingDown gates handleWebSocket: once set, the lame-duck period has
+// begun and new upgrade attempts get a 503 instead of a handshake, so a
+// browser extension retrying against the next candidate port doesn't race
+// a connection that's about to be torn down anyway.
+var shuttingDown atomic.Bool
+
+// shutdownClients enters the lame-duck period: it stops new /ws upgrades,
+// warns every authenticated client that the server is going away, then
+// (for every WebSocket client) follows up with a proper close frame so a browser
+// extension sees a clean disconnect instead of a reset socket and can
+// retry the handshake against the next port.
+func shutdownClients(reason string) {
+	shuttingDown.Store(true)
+
+	broadcastMessage(WSMessage{
+		Type:    "server_shutdown",
+		Payload: map[string]any{"reason": reason},
+	})
+
+	clientsLock.Lock()
+	targets := make([]Client, 0, len(clients))
+	for c := range clients {
+		targets = append(targets, c)
+	}
+	clientsLock.Unlock()
+
+	for _, c := range targets {
+		ws, ok := c.(*wsClient)
+		if !ok {
+			continue // SSE clients have no close frame; they drop on ctx cancellation
+		}
+		ws.conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseGoingAway, reason))
+	}
+}
+
+// forceCloseClients is the hard-timeout fallback: any WebSocket client still
+// in the registry after waitForDrain's deadline gets its underlying
+// connection closed outright instead of holding up process exit any longer.
+// SSE and gateway clients are not closed here. It returns how many
+// connections it closed.
+func forceCloseClients() int {
+	clientsLock.Lock()
+	targets := make([]Client, 0, len(clients))
+	for c := range clients {
+		targets = append(targets, c)
+	}
+	clientsLock.Unlock()
+
+	closed := 0
+	for _, c := range targets {
+		if ws, ok := c.(*wsClient); ok {
+			ws.conn.Close()
+			closed++
+		}
+	}
+	return closed
+}
+
+// waitForDrain blocks until every handleWebSocketMessages reader has exited
+// (having seen the close frame sent by shutdownClients) or timeout elapses,
+// whichever comes first. It reports whether the drain completed cleanly.
+func waitForDrain(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		connWG.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// CloserFunc tears down one shutdown-registered component. It's a plain
+// func rather than an io.Closer so callers can close over whatever
+// context/deadline a component's own Shutdown/Close method needs.
+type CloserFunc func() error
+
+// namedCloser pairs a CloserFunc with the label used in "didn't finish in
+// time" log lines, since a bare func doesn't know its own role.
+type namedCloser struct {
+	name   string
+	closer CloserFunc
+}
+
+// Manager coordinates graceful teardown of every component main registers
+// with it (the WS *http.Server, the log file, the Upwork monitor's
+// fsnotify watcher) so Shutdown can close them all in parallel and report
+// which ones missed the deadline, rather than waiting on them one at a
+// time in whatever order main happens to list them.
+type Manager struct {
+	mu      sync.Mutex
+	closers []namedCloser
+}
+
+// Register adds a component to be closed by the next Shutdown call. Safe
+// to call from multiple goroutines.
+func (m *Manager) Register(name string, c CloserFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closers = append(m.closers, namedCloser{name, c})
+}
+
+// Shutdown runs every registered closer in parallel and waits up to
+// timeout for all of them to return. It returns the names of whichever
+// closers were still running when the deadline passed; a straggler's
+// goroutine is left to finish on its own rather than abandoned mid-call,
+// it just no longer blocks the process exit. An empty result means every
+// component closed cleanly within the deadline.
+func (m *Manager) Shutdown(timeout time.Duration) (timedOut []string) {
+	m.mu.Lock()
+	targets := append([]namedCloser(nil), m.closers...)
+	m.mu.Unlock()
+
+	type result struct {
+		name string
+		err  error
+	}
+	done := make(chan result, len(targets))
+	for _, t := range targets {
+		go func(t namedCloser) {
+			done <- result{t.name, t.closer()}
+		}(t)
+	}
+
+	remaining := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		remaining[t.name] = true
+	}
+
+	deadline := time.After(timeout)
+	for range targets {
+		select {
+		case r := <-done:
+			delete(remaining, r.name)
+			if r.err != nil {
+				Warnf("config", "shutdown: %s: %v", r.name, r.err)
+			}
+		case <-deadline:
+			for name := range remaining {
+				timedOut = append(timedOut, name)
+			}
+			return timedOut
+		}
+	}
+	return nil
+}
+
+// WaitForDeath is Manager.Shutdown for subsystems that already satisfy
+// io.Closer (an *os.File, a net.Listener) rather than a bespoke CloserFunc,
+// so a caller with a handful of closers doesn't need to build its own
+// Manager just to get the same parallel-fan-out, bounded-wait behavior. It
+// returns the positional names ("closer 0", "closer 1", ...) of whichever
+// closers were still running when timeout elapsed.
+func WaitForDeath(timeout time.Duration, closers ...io.Closer) (timedOut []string) {
+	mgr := &Manager{}
+	for i, c := range closers {
+		mgr.Register(fmt.Sprintf("closer %d", i), c.Close)
+	}
+	return mgr.Shutdown(timeout)
+}
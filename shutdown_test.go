@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// closerFunc adapts a plain func() error to io.Closer for tests exercising
+// WaitForDeath, which (like Manager) takes subsystems rather than funcs.
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+func TestWaitForDrainReturnsTrueWhenEmpty(t *testing.T) {
+	if !waitForDrain(50 * time.Millisecond) {
+		t.Fatal("expected waitForDrain to succeed immediately with no in-flight readers")
+	}
+}
+
+func TestWaitForDrainTimesOut(t *testing.T) {
+	connWG.Add(1)
+	defer connWG.Done()
+
+	if waitForDrain(20 * time.Millisecond) {
+		t.Fatal("expected waitForDrain to time out while a reader is still registered")
+	}
+}
+
+func TestManagerShutdownRunsClosersInParallel(t *testing.T) {
+	mgr := &Manager{}
+	var a, b bool
+	// Each closer waits for the other to start, so both can only finish
+	// before the deadline if Shutdown runs them concurrently.
+	var started sync.WaitGroup
+	started.Add(2)
+	mgr.Register("a", func() error { started.Done(); started.Wait(); a = true; return nil })
+	mgr.Register("b", func() error { started.Done(); started.Wait(); b = true; return errors.New("boom") })
+
+	timedOut := mgr.Shutdown(time.Second)
+	if len(timedOut) != 0 {
+		t.Fatalf("expected no timed-out components, got %v", timedOut)
+	}
+	if !a || !b {
+		t.Fatalf("expected both closers to run, got a=%v b=%v", a, b)
+	}
+}
+
+func TestManagerShutdownReportsTimedOutComponents(t *testing.T) {
+	mgr := &Manager{}
+	mgr.Register("fast", func() error { return nil })
+	mgr.Register("slow", func() error {
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	})
+
+	timedOut := mgr.Shutdown(20 * time.Millisecond)
+	if len(timedOut) != 1 || timedOut[0] != "slow" {
+		t.Fatalf("expected only \"slow\" to be reported as timed out, got %v", timedOut)
+	}
+}
+
+func TestManagerShutdownWithNoClosers(t *testing.T) {
+	mgr := &Manager{}
+	if timedOut := mgr.Shutdown(10 * time.Millisecond); len(timedOut) != 0 {
+		t.Fatalf("expected no timed-out components with nothing registered, got %v", timedOut)
+	}
+}
+
+func TestWaitForDeathRunsClosersAndReportsTimeouts(t *testing.T) {
+	var closed int32
+	fast := closerFunc(func() error { atomic.AddInt32(&closed, 1); return nil })
+	slow := closerFunc(func() error {
+		time.Sleep(200 * time.Millisecond)
+		atomic.AddInt32(&closed, 1)
+		return nil
+	})
+
+	timedOut := WaitForDeath(20*time.Millisecond, fast, slow)
+	if len(timedOut) != 1 || timedOut[0] != "closer 1" {
+		t.Fatalf("expected the slow closer (index 1) to time out, got %v", timedOut)
+	}
+	if atomic.LoadInt32(&closed) != 1 {
+		t.Fatalf("expected only the fast closer to have finished, got %d", closed)
+	}
+}
+
+func TestForceCloseClientsWithEmptyRegistry(t *testing.T) {
+	clientsLock.Lock()
+	clients = make(map[Client]bool)
+	clientsLock.Unlock()
+
+	if n := forceCloseClients(); n != 0 {
+		t.Fatalf("expected an empty registry to report 0 closed, got %d", n)
+	}
+}
+
+func TestShuttingDownBlocksNewUpgrades(t *testing.T) {
+	shuttingDown.Store(true)
+	defer shuttingDown.Store(false)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	handleWebSocket(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 once shuttingDown is set, got %d", rec.Code)
+	}
+}
+
+func TestShutdownTimeoutDefault(t *testing.T) {
+	if got := shutdownTimeout(Config{}); got != 10*time.Second {
+		t.Fatalf("expected the 10s default for an unset ShutdownTimeoutSeconds, got %s", got)
+	}
+	if got := shutdownTimeout(Config{ShutdownTimeoutSeconds: 3}); got != 3*time.Second {
+		t.Fatalf("expected ShutdownTimeoutSeconds to be honored, got %s", got)
+	}
+}
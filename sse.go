@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// handleSSE streams every broadcast message as a Server-Sent Event. Unlike
+// the WebSocket path there's no browser-extension handshake to complete
+// first: curl-based integrations and CSP-restricted extensions are
+// authenticated the same way the rest of the plain-HTTP surface is (i.e.
+// not at all), which matches /health and /test/broadcast today.
+func handleSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	connID := nextConnID()
+	c := &sseClient{w: w, flush: flusher, addr: r.RemoteAddr, connID: connID}
+
+	clientsLock.Lock()
+	clients[c] = true
+	clientsLock.Unlock()
+	wsClientsGauge.Inc()
+	InfoFields("ws", "SSE client connected", map[string]any{"conn_addr": c.addr, "conn_id": connID})
+
+	defer func() {
+		clientsLock.Lock()
+		delete(clients, c)
+		clientsLock.Unlock()
+		wsClientsGauge.Dec()
+		InfoFields("ws", "SSE client disconnected", map[string]any{"conn_addr": c.addr, "conn_id": connID})
+	}()
+
+	sendMessage(c, WSMessage{
+		Type: "connected",
+		Payload: map[string]any{
+			"timestamp": time.Now().Format(time.RFC3339),
+		},
+	})
+
+	<-r.Context().Done()
+}
+
+// handleEventsSend is the client→server counterpart to handleSSE: since an
+// SSE stream is one-way, a curl-based client posts "ping" here instead of
+// writing onto the stream, and gets the "pong" back in the HTTP response
+// body rather than over the stream. Any other type, "hello_ack" included,
+// is logged as unknown and answered with an empty 200.
+func handleEventsSend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var msg WSMessage
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	switch msg.Type {
+	case "ping":
+		json.NewEncoder(w).Encode(WSMessage{
+			Type: "pong",
+			Payload: map[string]any{
+				"timestamp": time.Now().Format(time.RFC3339),
+			},
+		})
+	default:
+		Warnf("ws", "events/send: unknown msg %q from %s", msg.Type, r.RemoteAddr)
+		w.WriteHeader(http.StatusOK)
+	}
+}
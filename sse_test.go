@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandleSSEStreamsBroadcast(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	ctx, cancel := context.WithCancel(req.Context())
+	req = req.WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		handleSSE(rec, req)
+		close(done)
+	}()
+
+	// Wait for the client to register, then broadcast a message.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		clientsLock.Lock()
+		n := len(clients)
+		clientsLock.Unlock()
+		if n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("SSE client never registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	broadcastMessage(WSMessage{Type: "screenshot_detected", Payload: map[string]any{"timestamp": "12:00:00"}})
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	<-done
+
+	body := rec.Body.String()
+	if !strings.Contains(body, `"type":"connected"`) {
+		t.Fatalf("expected a connected event, got: %s", body)
+	}
+	if !strings.Contains(body, `"type":"screenshot_detected"`) {
+		t.Fatalf("expected the broadcast screenshot_detected event, got: %s", body)
+	}
+	if !strings.Contains(body, "data: ") {
+		t.Fatalf("expected SSE data: framing, got: %s", body)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// Gap is a period between two consecutive screenshots that exceeds the
+// configured minimum gap threshold — typically a stretch of billed time
+// with no proof-of-work screenshot.
+type Gap struct {
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+}
+
+// CadenceReport summarizes the cadence of a set of screenshot events:
+// volume per hour bucket, the mean/stddev of the interval between
+// consecutive shots, and any gaps wider than the configured threshold.
+type CadenceReport struct {
+	Buckets      map[string]int // keyed by hour, e.g. "2025-04-10T14"
+	MeanInterval time.Duration
+	StdDev       time.Duration
+	Gaps         []Gap
+}
+
+// AnalyzeCadence is a pure function over already-collected events so it can
+// be unit tested without touching the filesystem or the CLI. events need
+// not be pre-sorted.
+func AnalyzeCadence(events []ScreenshotEvent, minGap time.Duration) CadenceReport {
+	report := CadenceReport{Buckets: make(map[string]int)}
+	if len(events) == 0 {
+		return report
+	}
+
+	sorted := make([]ScreenshotEvent, len(events))
+	copy(sorted, events)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].TS.Before(sorted[j].TS) })
+
+	for _, e := range sorted {
+		report.Buckets[e.TS.Format("2006-01-02T15")]++
+	}
+
+	if len(sorted) < 2 {
+		return report
+	}
+
+	intervals := make([]time.Duration, 0, len(sorted)-1)
+	for i := 1; i < len(sorted); i++ {
+		d := sorted[i].TS.Sub(sorted[i-1].TS)
+		intervals = append(intervals, d)
+		if d > minGap {
+			report.Gaps = append(report.Gaps, Gap{
+				Start:    sorted[i-1].TS,
+				End:      sorted[i].TS,
+				Duration: d,
+			})
+		}
+	}
+
+	var sum time.Duration
+	for _, d := range intervals {
+		sum += d
+	}
+	mean := sum / time.Duration(len(intervals))
+
+	var variance float64
+	for _, d := range intervals {
+		diff := float64(d - mean)
+		variance += diff * diff
+	}
+	variance /= float64(len(intervals))
+
+	report.MeanInterval = mean
+	report.StdDev = time.Duration(math.Sqrt(variance))
+	return report
+}
+
+// runStats implements the `stats` subcommand: it aggregates screenshot
+// events across the rotated Upwork logs and prints a cadence report,
+// flagging any gap wider than --min-gap.
+func runStats(args []string) int {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	var logs logFlag
+	fs.Var(&logs, "log", "log file to analyze (repeatable); defaults to every upwork..*.log in the configured logs dir")
+	minGap := fs.Duration("min-gap", 15*time.Minute, "report any interval between consecutive shots longer than this")
+	tzStr := fs.String("tz", "", "IANA zone to display results in (default: local time)")
+	utcFlag := fs.Bool("utc", false, "shorthand for --tz=UTC")
+	fs.Parse(args)
+
+	loc, err := resolveLocation(*tzStr, *utcFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "stats: %v\n", err)
+		return 1
+	}
+
+	var events []ScreenshotEvent
+	if len(logs) > 0 {
+		for _, f := range logs {
+			events = append(events, getAllScreenshotTimestamps(f, time.Time{}, time.Time{})...)
+		}
+	} else {
+		dir, err := resolveLogsDir()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "stats: %v\n", err)
+			return 1
+		}
+		events = allScreenshotTimestampsAcrossLogs(dir, time.Time{}, time.Time{})
+	}
+
+	if len(events) == 0 {
+		fmt.Fprintln(os.Stderr, "stats: no screenshots found")
+		return 0
+	}
+
+	// Bucket hours and days in the display zone, not the logs' local zone.
+	for i := range events {
+		events[i].TS = events[i].TS.In(loc)
+	}
+
+	report := AnalyzeCadence(events, *minGap)
+
+	days := make(map[string]int)
+	hours := make([]string, 0, len(report.Buckets))
+	for hour := range report.Buckets {
+		hours = append(hours, hour)
+		days[hour[:len("2025-04-10")]] += report.Buckets[hour]
+	}
+	sort.Strings(hours)
+
+	fmt.Printf("%d screenshots across %d day(s), %d hour bucket(s)\n", len(events), len(days), len(hours))
+	fmt.Printf("mean interval: %s, stddev: %s\n", report.MeanInterval, report.StdDev)
+
+	if len(report.Gaps) == 0 {
+		fmt.Printf("no gaps over %s\n", *minGap)
+	} else {
+		fmt.Printf("%d gap(s) over %s:\n", len(report.Gaps), *minGap)
+		for _, g := range report.Gaps {
+			fmt.Printf("  %s -> %s (%s)\n", g.Start.In(loc).Format(time.RFC3339), g.End.In(loc).Format(time.RFC3339), g.Duration)
+		}
+	}
+	return 0
+}
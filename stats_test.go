@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func shotAt(t *testing.T, raw string) ScreenshotEvent {
+	return ScreenshotEvent{TS: mustLocalTime(t, raw), Log: "upwork..20250410.log", Raw: raw}
+}
+
+func TestAnalyzeCadence(t *testing.T) {
+	events := []ScreenshotEvent{
+		shotAt(t, "2025-04-10T10:00:00.000"),
+		shotAt(t, "2025-04-10T10:05:00.000"),
+		shotAt(t, "2025-04-10T10:10:00.000"),
+		shotAt(t, "2025-04-10T10:40:00.000"), // 30m gap
+	}
+
+	report := AnalyzeCadence(events, 15*time.Minute)
+
+	if got := report.Buckets["2025-04-10T10"]; got != 4 {
+		t.Fatalf("expected 4 in the 10:00 bucket, got %d", got)
+	}
+	if len(report.Gaps) != 1 {
+		t.Fatalf("expected 1 gap, got %d: %v", len(report.Gaps), report.Gaps)
+	}
+	if report.Gaps[0].Duration != 30*time.Minute {
+		t.Fatalf("expected a 30m gap, got %s", report.Gaps[0].Duration)
+	}
+	if report.MeanInterval <= 0 {
+		t.Fatalf("expected a positive mean interval, got %s", report.MeanInterval)
+	}
+}
+
+func TestAnalyzeCadenceEmpty(t *testing.T) {
+	report := AnalyzeCadence(nil, 15*time.Minute)
+	if len(report.Buckets) != 0 || len(report.Gaps) != 0 || report.MeanInterval != 0 {
+		t.Fatalf("expected a zero-value report for no events, got %+v", report)
+	}
+}
+
+func TestAnalyzeCadenceSingleEvent(t *testing.T) {
+	report := AnalyzeCadence([]ScreenshotEvent{shotAt(t, "2025-04-10T10:00:00.000")}, 15*time.Minute)
+	if len(report.Gaps) != 0 {
+		t.Fatalf("a single event can't have a gap, got %v", report.Gaps)
+	}
+	if report.Buckets["2025-04-10T10"] != 1 {
+		t.Fatalf("expected the single event to land in the 10:00 bucket")
+	}
+}
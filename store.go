@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is one detection persisted to disk so a reconnecting client can
+// replay everything it missed, mirroring the shape notifyEvent broadcasts
+// live.
+type Event struct {
+	Source    string         `json:"source"`
+	EventType string         `json:"eventType"`
+	Timestamp time.Time      `json:"timestamp"`
+	Extra     map[string]any `json:"extra,omitempty"`
+}
+
+// Store persists Events as one newline-delimited JSON file per UTC day
+// under its directory, so pruning old history is just deleting old files.
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// globalStore is nil until main() successfully provisions a cache
+// directory; notifyEvent and handleSubscribe both treat a nil store as
+// "persistence disabled" rather than failing.
+var globalStore *Store
+
+// newStore opens (creating if necessary) a Store rooted at dir. An empty
+// dir defaults to ~/.cache/time-whisperer/events via os.UserCacheDir.
+func newStore(dir string) (*Store, error) {
+	if dir == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(cacheDir, "time-whisperer", "events")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) pathFor(t time.Time) string {
+	return filepath.Join(s.dir, t.UTC().Format("2006-01-02")+".jsonl")
+}
+
+// Append records ev in the day-bucketed file for its timestamp.
+func (s *Store) Append(ev Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.pathFor(ev.Timestamp), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(ev)
+}
+
+// Since returns every persisted event strictly after t, oldest first.
+func (s *Store) Since(t time.Time) []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matches, _ := filepath.Glob(filepath.Join(s.dir, "*.jsonl"))
+	sort.Strings(matches)
+
+	cutoffDay := t.UTC().Truncate(24 * time.Hour)
+	var events []Event
+	for _, m := range matches {
+		if day, err := time.Parse("2006-01-02", strings.TrimSuffix(filepath.Base(m), ".jsonl")); err == nil && day.Before(cutoffDay) {
+			continue // the whole file predates t's day, nothing in it can match
+		}
+
+		f, err := os.Open(m)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var ev Event
+			if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+				continue
+			}
+			if ev.Timestamp.After(t) {
+				events = append(events, ev)
+			}
+		}
+		f.Close()
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+	return events
+}
+
+// Prune deletes every day file older than maxAge.
+func (s *Store) Prune(maxAge time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matches, _ := filepath.Glob(filepath.Join(s.dir, "*.jsonl"))
+	cutoff := time.Now().UTC().Add(-maxAge)
+	for _, m := range matches {
+		day, err := time.Parse("2006-01-02", strings.TrimSuffix(filepath.Base(m), ".jsonl"))
+		if err != nil {
+			continue
+		}
+		if day.Before(cutoff) {
+			os.Remove(m)
+		}
+	}
+}
+
+// StartPruner runs Prune once immediately and then every interval until ctx
+// is cancelled.
+func (s *Store) StartPruner(ctx context.Context, interval, maxAge time.Duration) {
+	s.Prune(maxAge)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.Prune(maxAge)
+			}
+		}
+	}()
+}
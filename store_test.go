@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStoreAppendAndSince(t *testing.T) {
+	s, err := newStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("newStore: %v", err)
+	}
+
+	base := time.Date(2025, 4, 10, 10, 0, 0, 0, time.UTC)
+	for i, evType := range []string{"screenshot", "screenshot", "screenshot"} {
+		if err := s.Append(Event{Source: "upwork", EventType: evType, Timestamp: base.Add(time.Duration(i) * time.Minute)}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	got := s.Since(base)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events strictly after %s, got %d: %+v", base, len(got), got)
+	}
+	if !got[0].Timestamp.Before(got[1].Timestamp) {
+		t.Fatalf("expected events ordered oldest first, got %+v", got)
+	}
+}
+
+func TestStoreSinceSkipsOlderDays(t *testing.T) {
+	s, err := newStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("newStore: %v", err)
+	}
+
+	old := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2025, 4, 10, 0, 0, 0, 0, time.UTC)
+	if err := s.Append(Event{Source: "upwork", EventType: "screenshot", Timestamp: old}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Append(Event{Source: "upwork", EventType: "screenshot", Timestamp: recent}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := s.Since(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC))
+	if len(got) != 1 || !got[0].Timestamp.Equal(recent) {
+		t.Fatalf("expected only the recent event, got %+v", got)
+	}
+}
+
+func TestStorePrune(t *testing.T) {
+	s, err := newStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("newStore: %v", err)
+	}
+
+	old := time.Now().UTC().AddDate(0, 0, -40)
+	recent := time.Now().UTC()
+	if err := s.Append(Event{Source: "upwork", EventType: "screenshot", Timestamp: old}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Append(Event{Source: "upwork", EventType: "screenshot", Timestamp: recent}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Prune(30 * 24 * time.Hour)
+
+	got := s.Since(time.Time{})
+	if len(got) != 1 || !got[0].Timestamp.Equal(recent) {
+		t.Fatalf("expected only the recent event to survive pruning, got %+v", got)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// TailScreenshots tails the newest Upwork log in dir, the same way
+// runMonitor does, and emits a ScreenshotEvent for each new "Electron
+// Screensnap succeeded" line as it appears. The returned channel is closed
+// when ctx is cancelled, so it can be range'd over by any front-end (the
+// `watch` subcommand today, a tray icon or webhook poster tomorrow).
+func TailScreenshots(ctx context.Context, dir string) <-chan ScreenshotEvent {
+	out := make(chan ScreenshotEvent)
+
+	go func() {
+		defer close(out)
+
+		w, err := fsnotify.NewWatcher()
+		if err != nil {
+			log.Printf("TailScreenshots: fsnotify: %v", err)
+			return
+		}
+		defer w.Close()
+
+		expandedDir := expandPath(dir)
+		if err := w.Add(expandedDir); err != nil {
+			log.Printf("TailScreenshots: watch %s: %v", expandedDir, err)
+			return
+		}
+
+		var (
+			current  *os.File
+			rdr      *bufio.Reader
+			lastSeen time.Time
+		)
+
+		openCurrent := func() error {
+			fname := findLatestLog(expandedDir)
+			if fname == "" {
+				return nil
+			}
+			if current != nil && current.Name() == fname {
+				return nil
+			}
+			if current != nil {
+				current.Close()
+				current, rdr = nil, nil
+			}
+			f, err := os.Open(fname)
+			if err != nil {
+				return err
+			}
+			if _, err := f.Seek(0, io.SeekEnd); err != nil {
+				f.Close()
+				return err
+			}
+			current, rdr = f, bufio.NewReaderSize(f, 64*1024)
+			return nil
+		}
+
+		if err := openCurrent(); err != nil {
+			log.Printf("TailScreenshots: initial open failed, will retry: %v", err)
+		}
+
+		// drainNew reads every newly-appended line on the current file,
+		// emitting a ScreenshotEvent for each screenshot line, until it
+		// catches up to EOF or ctx is cancelled.
+		drainNew := func() {
+			for current != nil {
+				if off, _ := current.Seek(0, io.SeekCurrent); off > 0 {
+					if fi, err := current.Stat(); err == nil && fi.Size() < off {
+						if _, err := current.Seek(0, io.SeekStart); err == nil {
+							rdr.Reset(current)
+						}
+					}
+				}
+
+				line, err := rdr.ReadString('\n')
+				if err != nil {
+					if err != io.EOF {
+						if openErr := openCurrent(); openErr != nil {
+							log.Printf("TailScreenshots: reopen after read error: %v", openErr)
+						}
+					}
+					return
+				}
+
+				if !strings.Contains(line, screenshotPattern) {
+					continue
+				}
+				ts := parseTS(line)
+				if ts.IsZero() || !ts.After(lastSeen) {
+					continue
+				}
+				lastSeen = ts
+
+				select {
+				case out <- ScreenshotEvent{TS: ts, Log: filepath.Base(current.Name()), Raw: line}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				if current != nil {
+					current.Close()
+				}
+				return
+
+			case ev := <-w.Events:
+				if ev.Op&(fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0 &&
+					strings.HasPrefix(filepath.Base(ev.Name), "upwork.") {
+					if err := openCurrent(); err != nil {
+						log.Printf("TailScreenshots: rescan after %s event: %v", ev.Op, err)
+					}
+				}
+				if current == nil || ev.Name != current.Name() || ev.Op&fsnotify.Write == 0 {
+					continue
+				}
+				drainNew()
+
+			case err := <-w.Errors:
+				log.Printf("TailScreenshots: watch error: %v", err)
+			}
+		}
+	}()
+
+	return out
+}
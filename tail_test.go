@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTailScreenshotsEmitsNewLines(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "tw-tail-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	logDir := filepath.Join(tmpDir, "logs")
+	if err := os.MkdirAll(logDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	logPath := filepath.Join(logDir, "upwork..20250523.log")
+	if err := os.WriteFile(logPath, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := TailScreenshots(ctx, logDir)
+	time.Sleep(100 * time.Millisecond) // watcher warm-up
+
+	appendScreenshot(t, logPath, "11:00:00.000")
+
+	select {
+	case ev := <-events:
+		if ev.Log != filepath.Base(logPath) {
+			t.Fatalf("expected log %s, got %s", filepath.Base(logPath), ev.Log)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for tailed screenshot event")
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gen2brain/beeep"
+)
+
+// runWatch implements the `watch` subcommand: it live-tails the current
+// Upwork log via TailScreenshots and fires a desktop notification (and
+// optionally a webhook POST) for every new screenshot.
+func runWatch(args []string) int {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	webhook := fs.String("webhook", "", "POST each screenshot event as JSON to this URL, e.g. a Slack/Discord incoming webhook")
+	fs.Parse(args)
+
+	dir, err := resolveLogsDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "watch: %v\n", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("watching %s for new screenshots (ctrl-c to stop)\n", dir)
+
+	for ev := range TailScreenshots(ctx, dir) {
+		fmt.Printf("%s\t%s\n", ev.TS.Format("15:04:05"), ev.Log)
+
+		if err := beeep.Notify("SneakTime", "Screenshot detected at "+ev.TS.Format("15:04:05"), ""); err != nil {
+			log.Printf("watch: desktop notification failed: %v", err)
+		}
+
+		if *webhook != "" {
+			go postWebhook(*webhook, ev)
+		}
+	}
+	return 0
+}
+
+// postWebhook forwards a screenshot event as a JSON POST body, the same
+// shape emitJSON writes, so users can wire this into Slack/Discord.
+func postWebhook(url string, ev ScreenshotEvent) {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		log.Printf("watch: marshal webhook payload: %v", err)
+		return
+	}
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("watch: webhook post to %s failed: %v", url, err)
+		return
+	}
+	resp.Body.Close()
+}